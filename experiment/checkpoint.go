@@ -0,0 +1,199 @@
+package experiment
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"github.com/damargulis/game/game"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type pairKey struct {
+	Player1 string `json:"player1"`
+	Config1 int    `json:"config1"`
+	Player2 string `json:"player2"`
+	Config2 int    `json:"config2"`
+	Seed    int64  `json:"seed"`
+}
+
+func keyOf(pair [2]Engine, seed int64) pairKey {
+	return pairKey{
+		Player1: pair[0].Type,
+		Config1: pair[0].Param,
+		Player2: pair[1].Type,
+		Config2: pair[1].Param,
+		Seed:    seed,
+	}
+}
+
+type checkpointEntry struct {
+	Pairing pairKey      `json:"pairing"`
+	Index   int          `json:"index"`
+	Seed    int64        `json:"seed"`
+	Opening game.Opening `json:"opening,omitempty"`
+	Result  game.Result  `json:"result"`
+}
+
+// Checkpoint remembers every finished game of an experiment so a rerun with
+// the same seed skips pairings already in the results file and replays the
+// games of an unfinished pairing instead of playing them again.
+type Checkpoint struct {
+	mu       sync.Mutex
+	fileName string
+	f        *os.File
+	done     map[pairKey]bool
+	games    map[pairKey]map[int]checkpointEntry
+}
+
+// OpenCheckpoint loads the results in base.jsonl and the unfinished games in
+// base.checkpoint.jsonl. Lines that fail to parse, such as one cut short by
+// a crash, are ignored.
+func OpenCheckpoint(base string) (*Checkpoint, error) {
+	c := &Checkpoint{
+		fileName: base + ".checkpoint.jsonl",
+		done:     map[pairKey]bool{},
+		games:    map[pairKey]map[int]checkpointEntry{},
+	}
+	err := readLines(base+".jsonl", func(line []byte) {
+		var r Record
+		if json.Unmarshal(line, &r) == nil {
+			c.done[pairKey{r.Player1, r.Config1, r.Player2, r.Config2, r.Seed}] = true
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = readLines(c.fileName, func(line []byte) {
+		var e checkpointEntry
+		if json.Unmarshal(line, &e) == nil {
+			if c.games[e.Pairing] == nil {
+				c.games[e.Pairing] = map[int]checkpointEntry{}
+			}
+			c.games[e.Pairing][e.Index] = e
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	c.f, err = os.OpenFile(c.fileName, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func readLines(fileName string, f func([]byte)) error {
+	file, err := os.Open(fileName)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		f(scanner.Bytes())
+	}
+	return scanner.Err()
+}
+
+func (c *Checkpoint) Done(pair [2]Engine, seed int64) bool {
+	if c == nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.done[keyOf(pair, seed)]
+}
+
+// Wrap returns a PlayFunc for one pairing that hands back saved results for
+// games already played and saves each new one as it finishes. A saved game
+// is only reused when its seed and opening match, so a changed experiment
+// plays fresh.
+func (c *Checkpoint) Wrap(play PlayFunc, pair [2]Engine, seed int64) PlayFunc {
+	if c == nil {
+		return play
+	}
+	key := keyOf(pair, seed)
+	index := 0
+	return func(p1, p2 string, config1, config2 int, gameSeed int64, opening game.Opening) game.Result {
+		i := index
+		index++
+		c.mu.Lock()
+		e, ok := c.games[key][i]
+		c.mu.Unlock()
+		if ok && e.Seed == gameSeed && sameOpening(e.Opening, opening) {
+			return e.Result
+		}
+		res := play(p1, p2, config1, config2, gameSeed, opening)
+		c.save(checkpointEntry{Pairing: key, Index: i, Seed: gameSeed, Opening: opening, Result: res})
+		return res
+	}
+}
+
+func (c *Checkpoint) save(e checkpointEntry) {
+	line, err := json.Marshal(e)
+	if err != nil {
+		panic(err)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.games[e.Pairing] == nil {
+		c.games[e.Pairing] = map[int]checkpointEntry{}
+	}
+	c.games[e.Pairing][e.Index] = e
+	if _, err := c.f.Write(append(line, '\n')); err != nil {
+		panic(err)
+	}
+}
+
+// Finish marks a pairing as written to the results file.
+func (c *Checkpoint) Finish(pair [2]Engine, seed int64) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.done[keyOf(pair, seed)] = true
+	delete(c.games, keyOf(pair, seed))
+}
+
+// Close closes the checkpoint file, removing it when complete is set since
+// every game in it has made it into the results.
+func (c *Checkpoint) Close(complete bool) error {
+	if c == nil {
+		return nil
+	}
+	err := c.f.Close()
+	if complete && err == nil {
+		err = os.Remove(c.fileName)
+	}
+	return err
+}
+
+// resumeSeed is the seed to play the experiment in base with. The first run
+// saves its seed to base.seed. A later run that was given no seed of its own,
+// so picked seed from the clock, takes the saved one instead, so that
+// running the same command again resumes it; one given a different seed is
+// refused rather than mixing its results in with the old ones.
+func resumeSeed(base string, seed int64, picked bool) (int64, error) {
+	fileName := base + ".seed"
+	data, err := os.ReadFile(fileName)
+	if os.IsNotExist(err) {
+		return seed, os.WriteFile(fileName, []byte(fmt.Sprintln(seed)), 0644)
+	} else if err != nil {
+		return 0, err
+	}
+	saved, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%v: %v", fileName, err)
+	}
+	if picked || saved == seed {
+		return saved, nil
+	}
+	return 0, fmt.Errorf("%v holds results played with seed %v, not %v; rerun without a seed or with that one, or use another output", base, saved, seed)
+}
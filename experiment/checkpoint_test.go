@@ -0,0 +1,99 @@
+package experiment
+
+import (
+	"github.com/damargulis/game/game"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const stopped = "stopped"
+
+// stopAfter plays like GamePlayer until it has played games games, then
+// panics with stopped as if the run had been killed.
+func stopAfter(games int) func(name string, rules game.Rules) PlayFunc {
+	return func(name string, rules game.Rules) PlayFunc {
+		play := GamePlayer(name, false, rules)
+		return func(p1, p2 string, config1, config2 int, seed int64, opening game.Opening) game.Result {
+			if games == 0 {
+				panic(stopped)
+			}
+			games--
+			return play(p1, p2, config1, config2, seed, opening)
+		}
+	}
+}
+
+func resumeConfig(dir string) Config {
+	return Config{
+		OutputDir:       dir,
+		GamesPerPairing: 3,
+		Games:           []GameConfig{{Name: "tictactoe"}},
+		Engines: []AxisConfig{
+			{Type: "Computer"},
+			{Type: "Alphabeta", From: 1, To: 2, Step: 1},
+		},
+	}
+}
+
+func TestResumeMatchesACleanRun(t *testing.T) {
+	dir := t.TempDir()
+	func() {
+		defer func() {
+			if v := recover(); v != stopped {
+				t.Fatalf("run ended with %v, want it stopped", v)
+			}
+		}()
+		RunConfigWith(resumeConfig(dir), stopAfter(10))
+	}()
+	// Run again, as the same command would, without a seed.
+	if err := RunConfig(resumeConfig(dir)); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "tictactoe.seed"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	seed, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clean := t.TempDir()
+	c := resumeConfig(clean)
+	c.Games[0].Seed = seed
+	if err := RunConfig(c); err != nil {
+		t.Fatal(err)
+	}
+	got := readRecords(t, filepath.Join(dir, "tictactoe"))
+	want := readRecords(t, filepath.Join(clean, "tictactoe"))
+	if len(want) != 9 {
+		t.Fatalf("clean run wrote %v records, want 9", len(want))
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("resumed records\n%+v\nwant a clean run's\n%+v", got, want)
+	}
+	csv, err := os.ReadFile(filepath.Join(dir, "tictactoe.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(string(csv), "\n"); lines != 10 {
+		t.Errorf("resumed csv has %v lines, want a header and 9 rows", lines)
+	}
+}
+
+func TestResumeRefusesAnotherSeed(t *testing.T) {
+	dir := t.TempDir()
+	c := resumeConfig(dir)
+	c.Seed = 1
+	if err := RunConfig(c); err != nil {
+		t.Fatal(err)
+	}
+	c.Seed = 2
+	if err := RunConfig(c); err == nil {
+		t.Error("RunConfig appended results played with another seed")
+	}
+}
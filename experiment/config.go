@@ -0,0 +1,457 @@
+package experiment
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/damargulis/game/game"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type GameConfig struct {
+	Name string `json:"name"`
+	// Variant picks the rules the game is played by. Every game is only
+	// played by its standard rules so far, so Variant may only be empty or
+	// "standard".
+	Variant  string          `json:"variant"`
+	Output   string          `json:"output"`
+	Seed     int64           `json:"seed"`
+	Openings *OpeningsConfig `json:"openings"`
+	// Adjudication and TimeControl replace the experiment's for this game.
+	Adjudication *AdjudicationConfig `json:"adjudication"`
+	TimeControl  *TimeControlConfig  `json:"time_control"`
+}
+
+// OpeningsConfig either lists the openings to use, each as indexes into the
+// possible moves at every ply, or asks for random ones of Plies moves.
+type OpeningsConfig struct {
+	Plies   int     `json:"plies"`
+	Depth   int     `json:"depth"`
+	MaxEval int     `json:"max_eval"`
+	List    [][]int `json:"list"`
+}
+
+type AxisConfig struct {
+	Type string `json:"type"`
+	From int    `json:"from"`
+	To   int    `json:"to"`
+	Step int    `json:"step"`
+}
+
+// AdjudicationConfig ends games once both engines' evaluations agree, with
+// a Depth ply search scoring for any engine that can't report its own; see
+// game.Adjudication.
+type AdjudicationConfig struct {
+	Depth     int `json:"depth"`
+	WinEval   int `json:"win_eval"`
+	WinMoves  int `json:"win_moves"`
+	DrawEval  int `json:"draw_eval"`
+	DrawMoves int `json:"draw_moves"`
+	MaxPlies  int `json:"max_plies"`
+}
+
+func (a *AdjudicationConfig) validate() error {
+	if a == nil {
+		return nil
+	}
+	if a.Depth < 0 || a.WinEval < 0 || a.WinMoves < 0 || a.DrawEval < 0 || a.DrawMoves < 0 || a.MaxPlies < 0 {
+		return fmt.Errorf("adjudication settings must not be negative")
+	}
+	if a.WinMoves > 0 && a.WinEval == 0 {
+		return fmt.Errorf("adjudication win_moves needs a positive win_eval")
+	}
+	return nil
+}
+
+func (a *AdjudicationConfig) rules() game.Adjudication {
+	if a == nil {
+		return game.Adjudication{}
+	}
+	return game.Adjudication{
+		Depth:     a.Depth,
+		WinEval:   a.WinEval,
+		WinMoves:  a.WinMoves,
+		DrawEval:  a.DrawEval,
+		DrawMoves: a.DrawMoves,
+		MaxPlies:  a.MaxPlies,
+	}
+}
+
+// TimeControlConfig holds durations such as "60s" or "500ms"; see
+// game.TimeControl.
+type TimeControlConfig struct {
+	Base      string `json:"base"`
+	Increment string `json:"increment"`
+	PerMove   string `json:"per_move"`
+}
+
+func (t *TimeControlConfig) parse() (game.TimeControl, error) {
+	var tc game.TimeControl
+	if t == nil {
+		return tc, nil
+	}
+	for _, d := range []struct {
+		name  string
+		value string
+		to    *time.Duration
+	}{
+		{"base", t.Base, &tc.Base},
+		{"increment", t.Increment, &tc.Increment},
+		{"per_move", t.PerMove, &tc.PerMove},
+	} {
+		if d.value == "" {
+			continue
+		}
+		v, err := time.ParseDuration(d.value)
+		if err != nil {
+			return tc, fmt.Errorf("time_control %v: %v", d.name, err)
+		}
+		if v < 0 {
+			return tc, fmt.Errorf("time_control %v must not be negative", d.name)
+		}
+		*d.to = v
+	}
+	if tc.Base == 0 && tc.PerMove == 0 {
+		return tc, fmt.Errorf("time_control needs a base or a per_move limit")
+	}
+	return tc, nil
+}
+
+// DeadlineConfig is a hard limit such as "5m" on any one move; see
+// game.Deadline. Fallback is "forfeit", the default, or "random".
+type DeadlineConfig struct {
+	Limit    string `json:"limit"`
+	Fallback string `json:"fallback"`
+}
+
+func (d *DeadlineConfig) parse() (game.Deadline, error) {
+	var dl game.Deadline
+	if d == nil {
+		return dl, nil
+	}
+	v, err := time.ParseDuration(d.Limit)
+	if err != nil {
+		return dl, fmt.Errorf("deadline limit: %v", err)
+	}
+	if v <= 0 {
+		return dl, fmt.Errorf("deadline limit must be positive")
+	}
+	dl.Limit = v
+	switch d.Fallback {
+	case "", "forfeit":
+	case "random":
+		dl.Random = true
+	default:
+		return dl, fmt.Errorf("deadline fallback %q is not forfeit or random", d.Fallback)
+	}
+	return dl, nil
+}
+
+type SPRTConfig struct {
+	Elo0     float64 `json:"elo0"`
+	Elo1     float64 `json:"elo1"`
+	Alpha    float64 `json:"alpha"`
+	Beta     float64 `json:"beta"`
+	MaxGames int     `json:"max_games"`
+}
+
+// Config describes a whole experiment: which games to play, the engine
+// axes to pair off in each, and how many games each pairing gets. Games
+// without their own seed draw one from Seed, in order. A zero Seed is picked
+// from the clock, and a rerun without one resumes with the seeds saved
+// alongside the results.
+type Config struct {
+	Seed            int64        `json:"seed"`
+	OutputDir       string       `json:"output_dir"`
+	Concurrency     int          `json:"concurrency"`
+	GamesPerPairing int          `json:"games_per_pairing"`
+	Print           bool         `json:"print"`
+	SPRT            *SPRTConfig  `json:"sprt"`
+	Games           []GameConfig `json:"games"`
+	Engines         []AxisConfig `json:"engines"`
+	// Adjudication ends games early when set; see game.Adjudication.
+	Adjudication *AdjudicationConfig `json:"adjudication"`
+	TimeControl  *TimeControlConfig  `json:"time_control"`
+	Deadline     *DeadlineConfig     `json:"deadline"`
+	// Ponder lets engines search on their opponent's time, which is only
+	// fair when each engine has a core to itself.
+	Ponder bool `json:"ponder"`
+	// Resign and DrawOffers let engines end games they judge settled.
+	Resign     bool `json:"resign"`
+	DrawOffers bool `json:"draw_offers"`
+	// External names programs to play as engines, speaking the protocol
+	// described at player.ExternalPlayer; engines of that type are given
+	// their parameter in seconds per move.
+	External map[string][]string `json:"external"`
+	// HTTP names web services to play as engines; see player.HTTPPlayer.
+	HTTP map[string]string `json:"http"`
+}
+
+func LoadConfig(fileName string) (Config, error) {
+	var c Config
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return c, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&c); err != nil {
+		return c, fmt.Errorf("%v: %v", fileName, err)
+	}
+	if err := c.Validate(); err != nil {
+		return c, fmt.Errorf("%v: %v", fileName, err)
+	}
+	return c, nil
+}
+
+// Validate checks c, counting its external and web engines as player types
+// without registering them; RunConfig does that.
+func (c Config) Validate() error {
+	for name, command := range c.External {
+		if len(command) == 0 {
+			return fmt.Errorf("external engine %v has no command", name)
+		}
+		if _, ok := c.HTTP[name]; ok {
+			return fmt.Errorf("engine %v is both external and web", name)
+		}
+	}
+	for name, url := range c.HTTP {
+		if url == "" {
+			return fmt.Errorf("web engine %v has no url", name)
+		}
+	}
+	if len(c.Games) == 0 {
+		return fmt.Errorf("no games listed")
+	}
+	outputs := map[string]bool{}
+	for _, g := range c.Games {
+		if _, ok := game.Games[g.Name]; !ok {
+			return fmt.Errorf("game %q not recognized", g.Name)
+		}
+		if g.Variant != "" && g.Variant != "standard" {
+			return fmt.Errorf("game %q has no variant %q; only \"standard\" is played so far", g.Name, g.Variant)
+		}
+		if o := g.Openings; o != nil {
+			if o.Plies < 0 || o.Depth < 0 || o.MaxEval < 0 {
+				return fmt.Errorf("game %q openings must not have negative plies, depth or max_eval", g.Name)
+			}
+			if _, err := g.openings(0); err != nil {
+				return err
+			}
+		}
+		if err := g.Adjudication.validate(); err != nil {
+			return fmt.Errorf("game %q: %v", g.Name, err)
+		}
+		if _, err := g.TimeControl.parse(); err != nil {
+			return fmt.Errorf("game %q: %v", g.Name, err)
+		}
+		if outputs[c.outputBase(g)] {
+			return fmt.Errorf("output %q used by more than one game", c.outputBase(g))
+		}
+		outputs[c.outputBase(g)] = true
+	}
+	if len(c.Engines) == 0 {
+		return fmt.Errorf("no engines listed")
+	}
+	for _, e := range c.Engines {
+		_, external := c.External[e.Type]
+		_, web := c.HTTP[e.Type]
+		if !(game.IsPlayerType(e.Type) || external || web) || e.Type == "Human" {
+			return fmt.Errorf("engine %q not recognized", e.Type)
+		}
+		if e.To < e.From {
+			return fmt.Errorf("engine %v has an empty range %v-%v", e.Type, e.From, e.To)
+		}
+		if e.Step < 0 {
+			return fmt.Errorf("engine %v has a negative step", e.Type)
+		}
+	}
+	if err := c.Adjudication.validate(); err != nil {
+		return err
+	}
+	if _, err := c.TimeControl.parse(); err != nil {
+		return err
+	}
+	if _, err := c.Deadline.parse(); err != nil {
+		return err
+	}
+	if c.Concurrency < 0 {
+		return fmt.Errorf("concurrency must not be negative")
+	}
+	if c.SPRT == nil && c.GamesPerPairing <= 0 {
+		return fmt.Errorf("games_per_pairing must be positive without an sprt section")
+	}
+	if c.SPRT != nil {
+		if len(Engines(c.axes())) < 2 {
+			return fmt.Errorf("sprt needs at least two engines to match")
+		}
+		s := c.SPRT
+		if s.Elo1 <= s.Elo0 {
+			return fmt.Errorf("sprt elo1 must be greater than elo0")
+		}
+		if s.Alpha <= 0 || s.Alpha >= 1 || s.Beta <= 0 || s.Beta >= 1 {
+			return fmt.Errorf("sprt alpha and beta must be between 0 and 1")
+		}
+		if s.MaxGames < 0 {
+			return fmt.Errorf("sprt max_games must not be negative")
+		}
+	}
+	return nil
+}
+
+func (c Config) outputBase(g GameConfig) string {
+	out := g.Output
+	if out == "" {
+		out = g.Name
+	}
+	return filepath.Join(c.OutputDir, out)
+}
+
+func (g GameConfig) openings(seed int64) (*Openings, error) {
+	if g.Openings == nil {
+		return nil, nil
+	}
+	list := make([]game.Opening, len(g.Openings.List))
+	for i, o := range g.Openings.List {
+		list[i] = o
+	}
+	return NewOpenings(g.Name, list, g.Openings.Plies, g.Openings.Depth, g.Openings.MaxEval, seed)
+}
+
+func (c Config) axes() []Axis {
+	axes := make([]Axis, len(c.Engines))
+	for i, e := range c.Engines {
+		axes[i] = Axis{Type: e.Type, From: e.From, To: e.To, Step: e.Step}
+	}
+	return axes
+}
+
+func (c Config) sprt() *SPRT {
+	if c.SPRT == nil {
+		return nil
+	}
+	return &SPRT{
+		Elo0:     c.SPRT.Elo0,
+		Elo1:     c.SPRT.Elo1,
+		Alpha:    c.SPRT.Alpha,
+		Beta:     c.SPRT.Beta,
+		MaxGames: c.SPRT.MaxGames,
+	}
+}
+
+// rules are the conditions g is played under; Validate has already checked
+// the time controls and deadline parse.
+func (c Config) rules(g GameConfig) game.Rules {
+	adj, tc := c.Adjudication, c.TimeControl
+	if g.Adjudication != nil {
+		adj = g.Adjudication
+	}
+	if g.TimeControl != nil {
+		tc = g.TimeControl
+	}
+	t, _ := tc.parse()
+	d, _ := c.Deadline.parse()
+	return game.Rules{Adjudication: adj.rules(), Time: t, Deadline: d, Ponder: c.Ponder, Resign: c.Resign, Draws: c.DrawOffers}
+}
+
+func GamePlayer(name string, print bool, rules game.Rules) PlayFunc {
+	return func(p1, p2 string, config1, config2 int, seed int64, opening game.Opening) game.Result {
+		g, err := game.New(name, p1, p2, config1, config2, seed)
+		if err != nil {
+			panic(err)
+		}
+		rules.Seed = seed
+		return game.PlayFrom(g, opening, print, rules)
+	}
+}
+
+// register adds c's external and web engines as player types.
+func (c Config) register() error {
+	for name, command := range c.External {
+		if err := game.RegisterExternal(name, command); err != nil {
+			return err
+		}
+	}
+	for name, url := range c.HTTP {
+		if err := game.RegisterHTTP(name, url); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RunConfig runs every game in c one after another, playing up to
+// Concurrency pairings of each at once, after registering its external and
+// web engines. Running the same config again resumes from its checkpoint
+// files.
+func RunConfig(c Config) error {
+	return RunConfigWith(c, func(name string, rules game.Rules) PlayFunc {
+		return GamePlayer(name, c.Print, rules)
+	})
+}
+
+// RunConfigWith is RunConfig with the games played by the PlayFunc player
+// returns for each game name, such as a Coordinator's.
+func RunConfigWith(c Config, player func(name string, rules game.Rules) PlayFunc) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	if err := c.register(); err != nil {
+		return err
+	}
+	if c.OutputDir != "" {
+		if err := os.MkdirAll(c.OutputDir, 0755); err != nil {
+			return err
+		}
+	}
+	engines := Engines(c.axes())
+	master := c.Seed
+	if master == 0 {
+		master = time.Now().UnixNano()
+		fmt.Println("Master seed", master)
+	}
+	rng := rand.New(rand.NewSource(master))
+	for _, g := range c.Games {
+		seed := rng.Int63()
+		if g.Seed != 0 {
+			seed = g.Seed
+		}
+		seed, err := resumeSeed(c.outputBase(g), seed, c.Seed == 0 && g.Seed == 0)
+		if err != nil {
+			return err
+		}
+		var book *Openings
+		if g.Openings != nil {
+			var err error
+			// Seeded apart from the pairings so turning openings on
+			// leaves every game's seed alone.
+			if book, err = g.openings(^seed); err != nil {
+				return err
+			}
+		}
+		cp, err := OpenCheckpoint(c.outputBase(g))
+		if err != nil {
+			return err
+		}
+		w, err := NewWriter(c.outputBase(g))
+		if err != nil {
+			cp.Close(false)
+			return err
+		}
+		play := dumpCrashes(player(g.Name, c.rules(g)), g.Name, c.outputBase(g))
+		err = RunConcurrent(play, g.Name, engines, c.GamesPerPairing, c.sprt(), book, seed, w, cp, c.Concurrency)
+		if cerr := w.Close(); err == nil {
+			err = cerr
+		}
+		if cerr := cp.Close(err == nil); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package experiment
+
+import (
+	"github.com/damargulis/game/game"
+	"testing"
+)
+
+func TestValidateRegistersNothing(t *testing.T) {
+	c := Config{
+		GamesPerPairing: 1,
+		Games:           []GameConfig{{Name: "tictactoe"}},
+		Engines:         []AxisConfig{{Type: "ValidateExternal"}, {Type: "ValidateWeb"}},
+		External:        map[string][]string{"ValidateExternal": {"engine"}},
+		HTTP:            map[string]string{"ValidateWeb": "http://127.0.0.1:1/move"},
+	}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Validate() = %v", err)
+	}
+	for _, name := range []string{"ValidateExternal", "ValidateWeb"} {
+		if game.IsPlayerType(name) {
+			t.Errorf("Validate registered %v", name)
+		}
+	}
+	c.External["ValidateExternal"] = nil
+	if err := c.Validate(); err == nil {
+		t.Error("Validate() accepted an external engine with no command")
+	}
+}
+
+func TestValidateTakesOnlyTheStandardVariant(t *testing.T) {
+	c := Config{
+		GamesPerPairing: 1,
+		Games:           []GameConfig{{Name: "connect4", Variant: "standard"}},
+		Engines:         []AxisConfig{{Type: "Computer"}},
+	}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Validate() = %v for the standard variant", err)
+	}
+	if got, want := c.outputBase(c.Games[0]), "connect4"; got != want {
+		t.Errorf("outputBase() = %q, want %q", got, want)
+	}
+	c.Games[0].Variant = "popout"
+	if err := c.Validate(); err == nil {
+		t.Error("Validate() accepted a variant no game is played by")
+	}
+}
@@ -0,0 +1,60 @@
+package experiment
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/damargulis/game/game"
+	"os"
+)
+
+// crashReport holds what it takes to replay a game that ended in a panic:
+// start it from Seed and play Moves, which begin with the Opening's.
+type crashReport struct {
+	Game    string       `json:"game"`
+	Player1 string       `json:"player1"`
+	Config1 int          `json:"config1"`
+	Player2 string       `json:"player2"`
+	Config2 int          `json:"config2"`
+	Seed    int64        `json:"seed"`
+	Opening game.Opening `json:"opening,omitempty"`
+	Reason  string       `json:"reason"`
+	Panic   string       `json:"panic"`
+	Board   string       `json:"board"`
+	Moves   []string     `json:"moves"`
+	Stack   string       `json:"stack"`
+}
+
+// dumpCrashes wraps play to write a report to base.crash-<seed>.json for
+// every game that ends in a panic.
+func dumpCrashes(play PlayFunc, name, base string) PlayFunc {
+	return func(p1, p2 string, config1, config2 int, seed int64, opening game.Opening) game.Result {
+		res := play(p1, p2, config1, config2, seed, opening)
+		if res.Crash == nil {
+			return res
+		}
+		fileName := fmt.Sprintf("%v.crash-%v.json", base, seed)
+		data, err := json.MarshalIndent(crashReport{
+			Game:    name,
+			Player1: p1,
+			Config1: config1,
+			Player2: p2,
+			Config2: config2,
+			Seed:    seed,
+			Opening: opening,
+			Reason:  res.Reason,
+			Panic:   res.Crash.Panic,
+			Board:   res.Crash.Board,
+			Moves:   res.Crash.Moves,
+			Stack:   res.Crash.Stack,
+		}, "", "  ")
+		if err == nil {
+			err = os.WriteFile(fileName, data, 0644)
+		}
+		if err != nil {
+			fmt.Println("Game crashed with", res.Crash.Panic, "but its report could not be saved:", err)
+		} else {
+			fmt.Printf("Game crashed with %v; see %v\n", res.Crash.Panic, fileName)
+		}
+		return res
+	}
+}
@@ -0,0 +1,57 @@
+package experiment
+
+import (
+	"encoding/json"
+	"github.com/damargulis/game/game"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDumpCrashesWritesOnlyCrashedGames(t *testing.T) {
+	base := filepath.Join(t.TempDir(), "tictactoe")
+	crash := &game.Crash{Panic: "boom", Stack: "goroutine 1", Board: "x..", Moves: []string{"{0,0}"}}
+	play := dumpCrashes(func(p1, p2 string, config1, config2 int, seed int64, opening game.Opening) game.Result {
+		if seed == 2 {
+			return game.Result{Winner: 2, Reason: game.EndPlayerCrash, Crash: crash}
+		}
+		return game.Result{Winner: 1}
+	}, "tictactoe", base)
+
+	if res := play("Computer", "Alphabeta", 0, 3, 1, nil); res.Winner != 1 {
+		t.Errorf("result %+v changed on the way through", res)
+	}
+	if _, err := os.Stat(base + ".crash-1.json"); !os.IsNotExist(err) {
+		t.Errorf("a game that didn't crash left a report: %v", err)
+	}
+
+	if res := play("Computer", "Alphabeta", 0, 3, 2, game.Opening{4}); res.Crash != crash {
+		t.Errorf("result %+v changed on the way through", res)
+	}
+	data, err := os.ReadFile(base + ".crash-2.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got crashReport
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := crashReport{
+		Game:    "tictactoe",
+		Player1: "Computer",
+		Config1: 0,
+		Player2: "Alphabeta",
+		Config2: 3,
+		Seed:    2,
+		Opening: game.Opening{4},
+		Reason:  game.EndPlayerCrash,
+		Panic:   "boom",
+		Board:   "x..",
+		Moves:   []string{"{0,0}"},
+		Stack:   "goroutine 1",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("report %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,218 @@
+package experiment
+
+import (
+	"fmt"
+	"github.com/damargulis/game/game"
+	"net"
+	"net/rpc"
+	"sync"
+	"time"
+)
+
+type Job struct {
+	ID      int64
+	Game    string
+	Player1 string
+	Config1 int
+	Player2 string
+	Config2 int
+	Seed    int64
+	Opening game.Opening
+	Rules   game.Rules
+}
+
+type JobReply struct {
+	Job  Job
+	Wait bool
+	Done bool
+}
+
+type JobResult struct {
+	ID     int64
+	Worker string
+	Result game.Result
+}
+
+type pendingJob struct {
+	job    Job
+	result chan game.Result
+}
+
+// Coordinator hands games out to workers over net/rpc. Each game is leased
+// to one worker at a time; if no result comes back before the lease runs
+// out the game goes to the next worker that asks, and only the first result
+// for a game is kept.
+type Coordinator struct {
+	Lease time.Duration
+	Poll  time.Duration
+
+	mu      sync.Mutex
+	nextID  int64
+	pending map[int64]*pendingJob
+	jobs    chan *pendingJob
+	done    chan struct{}
+	closed  bool
+}
+
+func NewCoordinator() *Coordinator {
+	return &Coordinator{
+		Lease:   10 * time.Minute,
+		Poll:    30 * time.Second,
+		pending: map[int64]*pendingJob{},
+		jobs:    make(chan *pendingJob),
+		done:    make(chan struct{}),
+	}
+}
+
+// Serve answers workers on l until l is closed.
+func (c *Coordinator) Serve(l net.Listener) error {
+	server := rpc.NewServer()
+	if err := server.RegisterName("Coordinator", &coordinatorService{c}); err != nil {
+		return err
+	}
+	server.Accept(l)
+	return nil
+}
+
+// Player returns a PlayFunc that plays each game of name on some worker
+// under rules, blocking until a result arrives.
+func (c *Coordinator) Player(name string, rules game.Rules) PlayFunc {
+	return func(p1, p2 string, config1, config2 int, seed int64, opening game.Opening) game.Result {
+		c.mu.Lock()
+		c.nextID++
+		p := &pendingJob{
+			job: Job{
+				ID:      c.nextID,
+				Game:    name,
+				Player1: p1,
+				Config1: config1,
+				Player2: p2,
+				Config2: config2,
+				Seed:    seed,
+				Opening: opening,
+				Rules:   rules,
+			},
+			result: make(chan game.Result, 1),
+		}
+		c.pending[p.job.ID] = p
+		c.mu.Unlock()
+		c.jobs <- p
+		return <-p.result
+	}
+}
+
+// Close tells workers there is nothing left to do.
+func (c *Coordinator) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.closed {
+		c.closed = true
+		close(c.done)
+	}
+}
+
+func (c *Coordinator) lease(p *pendingJob) {
+	time.AfterFunc(c.Lease, func() {
+		c.mu.Lock()
+		_, waiting := c.pending[p.job.ID]
+		c.mu.Unlock()
+		if waiting {
+			fmt.Println("Lease ran out on job", p.job.ID, "handing it out again")
+			select {
+			case c.jobs <- p:
+			case <-c.done:
+			}
+		}
+	})
+}
+
+type coordinatorService struct {
+	c *Coordinator
+}
+
+func (s *coordinatorService) GetJob(worker string, reply *JobReply) error {
+	select {
+	case p := <-s.c.jobs:
+		s.c.lease(p)
+		reply.Job = p.job
+	case <-s.c.done:
+		reply.Done = true
+	case <-time.After(s.c.Poll):
+		reply.Wait = true
+	}
+	return nil
+}
+
+func (s *coordinatorService) Submit(r JobResult, ok *bool) error {
+	s.c.mu.Lock()
+	p, waiting := s.c.pending[r.ID]
+	delete(s.c.pending, r.ID)
+	s.c.mu.Unlock()
+	if waiting {
+		p.result <- r.Result
+	}
+	*ok = waiting
+	return nil
+}
+
+// RunWorker plays games handed out by the coordinator at addr, running up
+// to parallel of them at once, until the coordinator says it is done or
+// cannot be reached for patience.
+func RunWorker(addr, name string, parallel int, patience time.Duration) error {
+	if parallel < 1 {
+		parallel = 1
+	}
+	errs := make(chan error, parallel)
+	for i := 0; i < parallel; i++ {
+		go func(i int) {
+			errs <- workerLoop(addr, fmt.Sprintf("%v/%v", name, i), patience)
+		}(i)
+	}
+	var err error
+	for i := 0; i < parallel; i++ {
+		if e := <-errs; err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+func workerLoop(addr, name string, patience time.Duration) error {
+	var client *rpc.Client
+	lastContact := time.Now()
+	for {
+		if client == nil {
+			var err error
+			client, err = rpc.Dial("tcp", addr)
+			if err != nil {
+				if time.Since(lastContact) > patience {
+					return err
+				}
+				time.Sleep(time.Second)
+				continue
+			}
+		}
+		var reply JobReply
+		if err := client.Call("Coordinator.GetJob", name, &reply); err != nil {
+			client.Close()
+			client = nil
+			continue
+		}
+		lastContact = time.Now()
+		if reply.Done {
+			client.Close()
+			return nil
+		}
+		if reply.Wait {
+			continue
+		}
+		job := reply.Job
+		res := GamePlayer(job.Game, false, job.Rules)(job.Player1, job.Player2, job.Config1, job.Config2, job.Seed, job.Opening)
+		var ok bool
+		if err := client.Call("Coordinator.Submit", JobResult{ID: job.ID, Worker: name, Result: res}, &ok); err != nil {
+			// The lease will run out and another worker will replay it.
+			client.Close()
+			client = nil
+		}
+	}
+}
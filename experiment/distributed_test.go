@@ -0,0 +1,106 @@
+package experiment
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+// readRecords reads the records written to base.jsonl, with the timings,
+// which vary from run to run, zeroed and in a fixed order.
+func readRecords(t *testing.T, base string) []Record {
+	t.Helper()
+	f, err := os.Open(base + ".jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var records []Record
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var r Record
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			t.Fatal(err)
+		}
+		r.TotalMillis, r.MoveMillis, r.P1MoveMillis, r.P2MoveMillis = 0, 0, 0, 0
+		records = append(records, r)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		a, b := records[i], records[j]
+		if a.Player1 != b.Player1 {
+			return a.Player1 < b.Player1
+		} else if a.Config1 != b.Config1 {
+			return a.Config1 < b.Config1
+		} else if a.Player2 != b.Player2 {
+			return a.Player2 < b.Player2
+		}
+		return a.Config2 < b.Config2
+	})
+	return records
+}
+
+func TestDistributedMatchesLocal(t *testing.T) {
+	config := func(dir string, concurrency int) Config {
+		return Config{
+			Seed:            7,
+			OutputDir:       dir,
+			Concurrency:     concurrency,
+			GamesPerPairing: 4,
+			Games:           []GameConfig{{Name: "tictactoe"}, {Name: "connect4"}},
+			Engines: []AxisConfig{
+				{Type: "Computer"},
+				{Type: "Alphabeta", From: 1, To: 2, Step: 1},
+			},
+		}
+	}
+	local := t.TempDir()
+	if err := RunConfig(config(local, 1)); err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	c := NewCoordinator()
+	c.Poll = 10 * time.Millisecond
+	go c.Serve(l)
+	workers := make(chan error, 2)
+	for _, name := range []string{"a", "b"} {
+		go func(name string) {
+			workers <- RunWorker(l.Addr().String(), name, 2, time.Minute)
+		}(name)
+	}
+	distributed := t.TempDir()
+	err = RunConfigWith(config(distributed, 3), c.Player)
+	c.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := <-workers; err != nil {
+			t.Errorf("worker: %v", err)
+		}
+	}
+
+	for _, name := range []string{"tictactoe", "connect4"} {
+		want := readRecords(t, filepath.Join(local, name))
+		got := readRecords(t, filepath.Join(distributed, name))
+		if len(want) == 0 {
+			t.Errorf("%v: no records written", name)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%v: distributed records\n%+v\nwant the local ones\n%+v", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package experiment
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+func (e Engine) String() string {
+	return fmt.Sprintf("%v:%v", e.Type, e.Param)
+}
+
+// Axis sweeps one engine type over its own parameter range, such as depth
+// for Alphabeta, seconds per move for MontecarloTime or nodes per move for
+// MontecarloNodes.
+type Axis struct {
+	Type           string
+	From, To, Step int
+}
+
+func (a Axis) Engines() []Engine {
+	step := a.Step
+	if step <= 0 {
+		step = 1
+	}
+	var engines []Engine
+	for param := a.From; param <= a.To; param += step {
+		engines = append(engines, Engine{Type: a.Type, Param: param})
+	}
+	return engines
+}
+
+// ParseAxes reads a comma separated list of Type:from-to[:step] entries, for
+// example "Alphabeta:4-12:2,MontecarloTime:1-5,ComboTime:3".
+func ParseAxes(s string) ([]Axis, error) {
+	var axes []Axis
+	for _, entry := range strings.Split(s, ",") {
+		parts := strings.Split(strings.TrimSpace(entry), ":")
+		if len(parts) < 2 || len(parts) > 3 || parts[0] == "" {
+			return nil, fmt.Errorf("bad engine axis %q, want Type:from-to[:step]", entry)
+		}
+		axis := Axis{Type: parts[0], Step: 1}
+		bounds := strings.SplitN(parts[1], "-", 2)
+		from, err := strconv.Atoi(bounds[0])
+		if err != nil {
+			return nil, fmt.Errorf("bad engine axis %q: %v", entry, err)
+		}
+		axis.From, axis.To = from, from
+		if len(bounds) == 2 {
+			if axis.To, err = strconv.Atoi(bounds[1]); err != nil {
+				return nil, fmt.Errorf("bad engine axis %q: %v", entry, err)
+			}
+		}
+		if len(parts) == 3 {
+			if axis.Step, err = strconv.Atoi(parts[2]); err != nil || axis.Step <= 0 {
+				return nil, fmt.Errorf("bad engine axis %q: step must be a positive integer", entry)
+			}
+		}
+		if axis.To < axis.From {
+			return nil, fmt.Errorf("bad engine axis %q: range is empty", entry)
+		}
+		axes = append(axes, axis)
+	}
+	return axes, nil
+}
+
+func Engines(axes []Axis) []Engine {
+	var engines []Engine
+	for _, axis := range axes {
+		engines = append(engines, axis.Engines()...)
+	}
+	return engines
+}
+
+// Pairings returns every ordered pair of engines, so each matchup is played
+// from both seats, along with each engine against itself.
+func Pairings(engines []Engine) [][2]Engine {
+	var pairs [][2]Engine
+	for i, a := range engines {
+		for _, b := range engines[:i] {
+			pairs = append(pairs, [2]Engine{a, b}, [2]Engine{b, a})
+		}
+		pairs = append(pairs, [2]Engine{a, a})
+	}
+	return pairs
+}
+
+// Matchups returns every pair of distinct engines once, for SPRT matches,
+// which alternate seats themselves and have nothing to test in an engine
+// against itself.
+func Matchups(engines []Engine) [][2]Engine {
+	var pairs [][2]Engine
+	for i, a := range engines {
+		for _, b := range engines[:i] {
+			pairs = append(pairs, [2]Engine{a, b})
+		}
+	}
+	return pairs
+}
@@ -0,0 +1,77 @@
+package experiment
+
+import (
+	"fmt"
+	"github.com/damargulis/game/game"
+	interfaces "github.com/damargulis/game/interfaces"
+	"math/rand"
+	"sync"
+)
+
+// Openings hands out the position each game of a pairing starts from. Game
+// i of every pairing gets the same opening, so between a pairing and its
+// mirror every opening is played from both seats. A curated List is used
+// in turn; otherwise openings are Plies random moves that a Depth ply
+// search scores within MaxEval of even.
+type Openings struct {
+	List    []game.Opening
+	Plies   int
+	Depth   int
+	MaxEval int
+
+	mu     sync.Mutex
+	start  interfaces.Game
+	rng    *rand.Rand
+	random []game.Opening
+}
+
+func NewOpenings(name string, list []game.Opening, plies, depth, maxEval int, seed int64) (*Openings, error) {
+	start, err := game.New(name, "Computer", "Computer", 0, 0, seed)
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range list {
+		if _, err := o.Apply(start); err != nil {
+			return nil, fmt.Errorf("%v: %v", name, err)
+		}
+	}
+	return &Openings{
+		List:    list,
+		Plies:   plies,
+		Depth:   depth,
+		MaxEval: maxEval,
+		start:   start,
+		rng:     rand.New(rand.NewSource(seed)),
+	}, nil
+}
+
+// Get returns the opening for game i, nil meaning the usual start.
+func (o *Openings) Get(i int) game.Opening {
+	if o == nil {
+		return nil
+	}
+	if len(o.List) > 0 {
+		return o.List[i%len(o.List)]
+	}
+	if o.Plies == 0 {
+		return nil
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for len(o.random) <= i {
+		o.random = append(o.random, game.RandomOpening(o.start, o.Plies, o.Depth, o.MaxEval, o.rng))
+	}
+	return o.random[i]
+}
+
+func sameOpening(a, b game.Opening) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
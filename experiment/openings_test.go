@@ -0,0 +1,109 @@
+package experiment
+
+import (
+	"github.com/damargulis/game/game"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestOpeningsCycleTheList(t *testing.T) {
+	list := []game.Opening{{0}, {1, 2}, {3}}
+	book, err := NewOpenings("connect4", list, 0, 0, 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 7; i++ {
+		if got := book.Get(i); !reflect.DeepEqual(got, list[i%3]) {
+			t.Errorf("Get(%v) = %v, want %v", i, got, list[i%3])
+		}
+	}
+	var none *Openings
+	if got := none.Get(2); got != nil {
+		t.Errorf("no book gave %v", got)
+	}
+}
+
+func TestOpeningsRejectIllegalLists(t *testing.T) {
+	if _, err := NewOpenings("connect4", []game.Opening{{0}, {99}}, 0, 0, 0, 1); err == nil {
+		t.Error("a list with no move 99 in connect4 was accepted")
+	}
+	if _, err := NewOpenings("chess", nil, 2, 0, 0, 1); err == nil {
+		t.Error("an unknown game was accepted")
+	}
+}
+
+// Random openings depend only on the seed and the game's index, however
+// and from however many goroutines they are asked for.
+func TestRandomOpeningsFollowTheSeed(t *testing.T) {
+	newBook := func(seed int64) *Openings {
+		book, err := NewOpenings("connect4", nil, 4, 2, 2, seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return book
+	}
+	serial := newBook(5)
+	var want []game.Opening
+	for i := 0; i < 8; i++ {
+		want = append(want, serial.Get(i))
+		if len(want[i]) != 4 {
+			t.Fatalf("Get(%v) = %v, want 4 plies", i, want[i])
+		}
+	}
+
+	concurrent := newBook(5)
+	got := make([]game.Opening, len(want))
+	var wg sync.WaitGroup
+	for i := len(want) - 1; i >= 0; i-- {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			got[i] = concurrent.Get(i)
+		}(i)
+	}
+	wg.Wait()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("openings asked for concurrently %v, want %v", got, want)
+	}
+	if again := serial.Get(3); !reflect.DeepEqual(again, want[3]) {
+		t.Errorf("Get(3) = %v the second time, want %v", again, want[3])
+	}
+
+	other := newBook(6)
+	same := true
+	for i := range want {
+		same = same && reflect.DeepEqual(other.Get(i), want[i])
+	}
+	if same {
+		t.Error("seeds 5 and 6 gave the same openings")
+	}
+}
+
+// Both games of a pair in a match, one from each seat, start from the same
+// opening.
+func TestMatchPlaysEachOpeningFromBothSeats(t *testing.T) {
+	book, err := NewOpenings("connect4", nil, 2, 0, 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var openings []game.Opening
+	var seats []string
+	play := func(p1, p2 string, config1, config2 int, seed int64, opening game.Opening) game.Result {
+		openings = append(openings, opening)
+		seats = append(seats, p1)
+		return game.Result{}
+	}
+	Match(play, "connect4", Engine{"A", 1}, Engine{"B", 1}, SPRT{Alpha: 0.05, Beta: 0.05, Elo1: 10, MaxGames: 6}, 1, book)
+	if len(openings) != 6 {
+		t.Fatalf("played %v games, want 6", len(openings))
+	}
+	for i := 0; i < 6; i += 2 {
+		if !reflect.DeepEqual(openings[i], openings[i+1]) || seats[i] == seats[i+1] {
+			t.Errorf("games %v and %v: %v opened %v, %v opened %v", i, i+1, seats[i], openings[i], seats[i+1], openings[i+1])
+		}
+		if want := book.Get(i / 2); !reflect.DeepEqual(openings[i], want) {
+			t.Errorf("game %v opened %v, want the book's %v", i, openings[i], want)
+		}
+	}
+}
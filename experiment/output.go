@@ -0,0 +1,243 @@
+package experiment
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/damargulis/game/game"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Record struct {
+	Game         string  `json:"game"`
+	Player1      string  `json:"player1"`
+	Config1      int     `json:"config1"`
+	Player2      string  `json:"player2"`
+	Config2      int     `json:"config2"`
+	Seed         int64   `json:"seed"`
+	Games        int     `json:"games"`
+	P1Wins       int     `json:"p1_wins"`
+	P2Wins       int     `json:"p2_wins"`
+	Draws        int     `json:"draws"`
+	MeanMargin   float64 `json:"mean_margin"`
+	TotalMillis  float64 `json:"total_ms"`
+	Moves        int     `json:"moves"`
+	MoveMillis   float64 `json:"ms_per_move"`
+	P1Nodes      int64   `json:"p1_nodes"`
+	P2Nodes      int64   `json:"p2_nodes"`
+	LLR          float64 `json:"llr,omitempty"`
+	Elo          float64 `json:"elo,omitempty"`
+	EloError     float64 `json:"elo_error,omitempty"`
+	Decision     string  `json:"decision,omitempty"`
+	P1MoveMillis float64 `json:"p1_ms_per_move"`
+	P2MoveMillis float64 `json:"p2_ms_per_move"`
+	Reasons      Reasons `json:"reasons,omitempty"`
+	P1Timeouts   int     `json:"p1_timeouts,omitempty"`
+	P2Timeouts   int     `json:"p2_timeouts,omitempty"`
+
+	margin int
+	think  [2]time.Duration
+	turns  [2]int
+}
+
+func (r *Record) Add(res game.Result) {
+	r.Games++
+	switch res.Winner {
+	case 0:
+		r.Draws++
+	case 1:
+		r.P1Wins++
+	case 2:
+		r.P2Wins++
+	}
+	r.margin += res.Margin
+	r.MeanMargin = float64(r.margin) / float64(r.Games)
+	r.TotalMillis += millis(res.Duration)
+	r.Moves += res.Moves
+	for i := range r.think {
+		r.think[i] += res.MoveTime[i]
+		r.turns[i] += res.Turns[i]
+	}
+	if r.Moves > 0 {
+		r.MoveMillis = millis(r.think[0]+r.think[1]) / float64(r.Moves)
+	}
+	if r.turns[0] > 0 {
+		r.P1MoveMillis = millis(r.think[0]) / float64(r.turns[0])
+	}
+	if r.turns[1] > 0 {
+		r.P2MoveMillis = millis(r.think[1]) / float64(r.turns[1])
+	}
+	r.P1Nodes += res.Nodes[0]
+	r.P2Nodes += res.Nodes[1]
+	r.P1Timeouts += res.Timeouts[0]
+	r.P2Timeouts += res.Timeouts[1]
+	if res.Reason != "" {
+		if r.Reasons == nil {
+			r.Reasons = Reasons{}
+		}
+		r.Reasons[res.Reason]++
+	}
+}
+
+// Reasons counts the games that ended some way other than by the rules,
+// such as by adjudication.
+type Reasons map[string]int
+
+func (r Reasons) String() string {
+	var keys []string
+	for k := range r {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = fmt.Sprintf("%v=%v", k, r[k])
+	}
+	return strings.Join(parts, ";")
+}
+
+func ParseReasons(s string) (Reasons, error) {
+	if s == "" {
+		return nil, nil
+	}
+	r := Reasons{}
+	for _, part := range strings.Split(s, ";") {
+		k, v, ok := strings.Cut(part, "=")
+		n, err := strconv.Atoi(v)
+		if !ok || err != nil {
+			return nil, fmt.Errorf("bad reason count %q", part)
+		}
+		r[k] += n
+	}
+	return r, nil
+}
+
+var header = []string{
+	"game", "player1", "config1", "player2", "config2", "seed", "games",
+	"p1_wins", "p2_wins", "draws", "mean_margin", "total_ms", "moves",
+	"ms_per_move", "p1_nodes", "p2_nodes", "llr", "elo", "elo_error",
+	"decision", "p1_ms_per_move", "p2_ms_per_move", "reasons",
+	"p1_timeouts", "p2_timeouts",
+}
+
+func (r Record) row() []string {
+	return []string{
+		r.Game,
+		r.Player1,
+		strconv.Itoa(r.Config1),
+		r.Player2,
+		strconv.Itoa(r.Config2),
+		strconv.FormatInt(r.Seed, 10),
+		strconv.Itoa(r.Games),
+		strconv.Itoa(r.P1Wins),
+		strconv.Itoa(r.P2Wins),
+		strconv.Itoa(r.Draws),
+		strconv.FormatFloat(r.MeanMargin, 'f', 3, 64),
+		strconv.FormatFloat(r.TotalMillis, 'f', 3, 64),
+		strconv.Itoa(r.Moves),
+		strconv.FormatFloat(r.MoveMillis, 'f', 3, 64),
+		strconv.FormatInt(r.P1Nodes, 10),
+		strconv.FormatInt(r.P2Nodes, 10),
+		strconv.FormatFloat(r.LLR, 'f', 3, 64),
+		strconv.FormatFloat(r.Elo, 'f', 1, 64),
+		strconv.FormatFloat(r.EloError, 'f', 1, 64),
+		r.Decision,
+		strconv.FormatFloat(r.P1MoveMillis, 'f', 3, 64),
+		strconv.FormatFloat(r.P2MoveMillis, 'f', 3, 64),
+		r.Reasons.String(),
+		strconv.Itoa(r.P1Timeouts),
+		strconv.Itoa(r.P2Timeouts),
+	}
+}
+
+func millis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+type Writer struct {
+	mu    sync.Mutex
+	csv   *os.File
+	jsonl *os.File
+}
+
+// NewWriter opens base.csv and base.jsonl for appending. A missing file is
+// created complete with its header and renamed into place, so a reader never
+// sees a half-written header.
+func NewWriter(base string) (*Writer, error) {
+	c, err := openAppend(base+".csv", header)
+	if err != nil {
+		return nil, err
+	}
+	j, err := openAppend(base+".jsonl", nil)
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+	return &Writer{csv: c, jsonl: j}, nil
+}
+
+func openAppend(fileName string, head []string) (*os.File, error) {
+	if _, err := os.Stat(fileName); os.IsNotExist(err) {
+		tmp, err := os.CreateTemp(filepath.Dir(fileName), filepath.Base(fileName)+".tmp")
+		if err != nil {
+			return nil, err
+		}
+		if head != nil {
+			w := csv.NewWriter(tmp)
+			w.Write(head)
+			w.Flush()
+			if err := w.Error(); err != nil {
+				tmp.Close()
+				os.Remove(tmp.Name())
+				return nil, err
+			}
+		}
+		if err := tmp.Close(); err != nil {
+			os.Remove(tmp.Name())
+			return nil, err
+		}
+		if err := os.Rename(tmp.Name(), fileName); err != nil {
+			os.Remove(tmp.Name())
+			return nil, err
+		}
+	}
+	return os.OpenFile(fileName, os.O_APPEND|os.O_WRONLY, 0644)
+}
+
+// Write appends r to both files. Each line goes out in a single write so
+// concurrent appenders never interleave within a line.
+func (w *Writer) Write(r Record) error {
+	var line bytes.Buffer
+	cw := csv.NewWriter(&line)
+	cw.Write(r.row())
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := w.csv.Write(line.Bytes()); err != nil {
+		return err
+	}
+	j, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	_, err = w.jsonl.Write(append(j, '\n'))
+	return err
+}
+
+func (w *Writer) Close() error {
+	err := w.csv.Close()
+	if jerr := w.jsonl.Close(); err == nil {
+		err = jerr
+	}
+	return err
+}
@@ -0,0 +1,164 @@
+package experiment
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"github.com/damargulis/game/game"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func testRecord(seed int64) Record {
+	r := Record{Game: "tictactoe", Player1: "Alphabeta", Config1: 2, Player2: "Computer", Seed: seed}
+	r.Add(game.Result{Winner: 1, Moves: 5, Duration: time.Millisecond, Turns: [2]int{3, 2}})
+	r.Add(game.Result{Winner: 0, Moves: 9, Reason: game.EndTimeForfeit, Timeouts: [2]int{0, 1}})
+	return r
+}
+
+func readCSV(t *testing.T, fileName string) [][]string {
+	t.Helper()
+	f, err := os.Open(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rows
+}
+
+func readJSONL(t *testing.T, fileName string) []Record {
+	t.Helper()
+	f, err := os.Open(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var records []Record
+	lines := bufio.NewScanner(f)
+	for lines.Scan() {
+		var r Record
+		if err := json.Unmarshal(lines.Bytes(), &r); err != nil {
+			t.Fatalf("%q: %v", lines.Text(), err)
+		}
+		records = append(records, r)
+	}
+	return records
+}
+
+// exported is r with only the fields that are written out.
+func exported(r Record) Record {
+	r.margin, r.think, r.turns = 0, [2]time.Duration{}, [2]int{}
+	return r
+}
+
+func TestHeaderNamesEveryColumn(t *testing.T) {
+	if got := len(testRecord(1).row()); got != len(header) {
+		t.Fatalf("rows have %v columns, the header %v", got, len(header))
+	}
+	var tags []string
+	rt := reflect.TypeOf(Record{})
+	for i := 0; i < rt.NumField(); i++ {
+		if tag := rt.Field(i).Tag.Get("json"); tag != "" {
+			tags = append(tags, strings.TrimSuffix(tag, ",omitempty"))
+		}
+	}
+	if !reflect.DeepEqual(tags, header) {
+		t.Errorf("header %v, want the json names %v", header, tags)
+	}
+}
+
+func TestWriterAppendsUnderOneHeader(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "results")
+	var want []Record
+	for run := 0; run < 2; run++ {
+		w, err := NewWriter(base)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 2; i++ {
+			r := testRecord(int64(len(want)))
+			if err := w.Write(r); err != nil {
+				t.Fatal(err)
+			}
+			want = append(want, exported(r))
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rows := readCSV(t, base+".csv")
+	if len(rows) != len(want)+1 || !reflect.DeepEqual(rows[0], header) {
+		t.Fatalf("csv has %v rows starting %v, want a header and %v records", len(rows), rows[0], len(want))
+	}
+	for i, r := range want {
+		if !reflect.DeepEqual(rows[i+1], r.row()) {
+			t.Errorf("csv row %v = %v, want %v", i+1, rows[i+1], r.row())
+		}
+	}
+	if got := readJSONL(t, base+".jsonl"); !reflect.DeepEqual(got, want) {
+		t.Errorf("jsonl records %+v, want %+v", got, want)
+	}
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Errorf("left %v files behind, want only the csv and jsonl", len(files))
+	}
+}
+
+// Writers on the same files, as concurrent runs have, never interleave
+// within a line.
+func TestConcurrentWritersKeepLinesWhole(t *testing.T) {
+	base := filepath.Join(t.TempDir(), "results")
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		w, err := NewWriter(base)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			defer w.Close()
+			for j := 0; j < 50; j++ {
+				if err := w.Write(testRecord(seed)); err != nil {
+					t.Error(err)
+				}
+			}
+		}(int64(i))
+	}
+	wg.Wait()
+	rows := readCSV(t, base+".csv")
+	if len(rows) != 201 || !reflect.DeepEqual(rows[0], header) {
+		t.Errorf("csv has %v rows starting %v, want a header and 200 records", len(rows), rows[0])
+	}
+	if got := len(readJSONL(t, base+".jsonl")); got != 200 {
+		t.Errorf("jsonl has %v records, want 200", got)
+	}
+}
+
+func TestReasonsRoundTrip(t *testing.T) {
+	r := Reasons{game.EndTimeForfeit: 2, game.EndPlayerCrash: 1}
+	if s := r.String(); s != "player_crash=1;time_forfeit=2" {
+		t.Errorf("String() = %q", s)
+	}
+	got, err := ParseReasons(r.String())
+	if err != nil || !reflect.DeepEqual(got, r) {
+		t.Errorf("ParseReasons(%q) = %v, %v", r.String(), got, err)
+	}
+	if _, err := ParseReasons("adjudicated"); err == nil {
+		t.Error("ParseReasons took a reason without a count")
+	}
+}
@@ -0,0 +1,109 @@
+package experiment
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+)
+
+// Fixed plays games games with a as Player 1 and b as Player 2, game i
+// starting from book's opening i.
+func Fixed(play PlayFunc, name string, a, b Engine, games int, seed int64, book *Openings) Record {
+	record := Record{
+		Game:    name,
+		Player1: a.Type,
+		Config1: a.Param,
+		Player2: b.Type,
+		Config2: b.Param,
+		Seed:    seed,
+	}
+	rng := rand.New(rand.NewSource(seed))
+	for i := 0; i < games; i++ {
+		record.Add(play(a.Type, b.Type, a.Param, b.Param, rng.Int63(), book.Get(i)))
+	}
+	return record
+}
+
+// pairings are the pairings Run plays: Pairings for fixed games, or
+// Matchups for SPRT matches.
+func pairings(engines []Engine, sprt *SPRT) [][2]Engine {
+	if sprt == nil {
+		return Pairings(engines)
+	}
+	return Matchups(engines)
+}
+
+// Run plays every pairing of engines and writes one record per pairing. With
+// a nil sprt each pairing is a fixed number of games, otherwise it is an SPRT
+// match. Pairings cp has already finished are skipped.
+func Run(play PlayFunc, name string, engines []Engine, games int, sprt *SPRT, book *Openings, seed int64, w *Writer, cp *Checkpoint) error {
+	fmt.Println("Running Experiment", name, "with engines", engines, "seed", seed)
+	rng := rand.New(rand.NewSource(seed))
+	for _, pair := range pairings(engines, sprt) {
+		if err := runPairing(play, name, pair, games, sprt, book, rng.Int63(), w, cp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RunConcurrent is Run with up to workers pairings in flight. Pairing seeds
+// are drawn in order up front, so results match a sequential run.
+func RunConcurrent(play PlayFunc, name string, engines []Engine, games int, sprt *SPRT, book *Openings, seed int64, w *Writer, cp *Checkpoint, workers int) error {
+	if workers <= 1 {
+		return Run(play, name, engines, games, sprt, book, seed, w, cp)
+	}
+	fmt.Println("Running Experiment", name, "with engines", engines, "seed", seed)
+	pairs := pairings(engines, sprt)
+	rng := rand.New(rand.NewSource(seed))
+	jobs := make(chan int)
+	errs := make(chan error, len(pairs))
+	pairSeeds := make([]int64, len(pairs))
+	for i := range pairs {
+		pairSeeds[i] = rng.Int63()
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				if err := runPairing(play, name, pairs[j], games, sprt, book, pairSeeds[j], w, cp); err != nil {
+					errs <- err
+				}
+			}
+		}()
+	}
+	for j := range pairs {
+		jobs <- j
+	}
+	close(jobs)
+	wg.Wait()
+	close(errs)
+	return <-errs
+}
+
+func runPairing(play PlayFunc, name string, pair [2]Engine, games int, sprt *SPRT, book *Openings, seed int64, w *Writer, cp *Checkpoint) error {
+	if cp.Done(pair, seed) {
+		fmt.Println("Already finished", pair[0], "vs", pair[1])
+		return nil
+	}
+	record := playPairing(cp.Wrap(play, pair, seed), name, pair, games, sprt, book, seed)
+	if err := w.Write(record); err != nil {
+		return err
+	}
+	cp.Finish(pair, seed)
+	return nil
+}
+
+func playPairing(play PlayFunc, name string, pair [2]Engine, games int, sprt *SPRT, book *Openings, seed int64) Record {
+	var record Record
+	if sprt == nil {
+		record = Fixed(play, name, pair[0], pair[1], games, seed, book)
+	} else {
+		record = Match(play, name, pair[0], pair[1], *sprt, seed, book)
+		fmt.Printf("%v %v vs %v: %v after %v games (LLR %.2f)\n", name, pair[0], pair[1], record.Decision, record.Games, record.LLR)
+	}
+	fmt.Println("Finished", pair[0], "vs", pair[1])
+	return record
+}
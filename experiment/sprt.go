@@ -0,0 +1,117 @@
+package experiment
+
+import (
+	"github.com/damargulis/game/game"
+	"math"
+	"math/rand"
+)
+
+type PlayFunc func(p1, p2 string, config1, config2 int, seed int64, opening game.Opening) game.Result
+
+type Engine struct {
+	Type  string
+	Param int
+}
+
+// SPRT tests H0: elo(A - B) <= Elo0 against H1: elo(A - B) >= Elo1, where a
+// draw counts as half a win. Alpha and Beta are the false positive and false
+// negative rates.
+type SPRT struct {
+	Elo0, Elo1  float64
+	Alpha, Beta float64
+	MaxGames    int
+}
+
+const (
+	AcceptH1     = "H1"
+	AcceptH0     = "H0"
+	Inconclusive = "inconclusive"
+)
+
+func expectedScore(elo float64) float64 {
+	return 1 / (1 + math.Pow(10, -elo/400))
+}
+
+func (t SPRT) Bounds() (float64, float64) {
+	return math.Log(t.Beta / (1 - t.Alpha)), math.Log((1 - t.Beta) / t.Alpha)
+}
+
+func (t SPRT) LLR(wins, draws, losses int) float64 {
+	s0 := expectedScore(t.Elo0)
+	s1 := expectedScore(t.Elo1)
+	w := float64(wins) + float64(draws)/2
+	l := float64(losses) + float64(draws)/2
+	return w*math.Log(s1/s0) + l*math.Log((1-s1)/(1-s0))
+}
+
+func (t SPRT) Decide(wins, draws, losses int) (float64, string) {
+	llr := t.LLR(wins, draws, losses)
+	lower, upper := t.Bounds()
+	if llr >= upper {
+		return llr, AcceptH1
+	} else if llr <= lower {
+		return llr, AcceptH0
+	}
+	return llr, ""
+}
+
+// Elo returns the performance difference implied by a score along with the
+// half width of its 95% confidence interval.
+func Elo(wins, draws, losses int) (float64, float64) {
+	n := float64(wins + draws + losses)
+	if n == 0 {
+		return 0, 0
+	}
+	s := (float64(wins) + float64(draws)/2) / n
+	variance := (float64(wins)*(1-s)*(1-s) + float64(draws)*(0.5-s)*(0.5-s) + float64(losses)*s*s) / n
+	margin := 1.96 * math.Sqrt(variance/n)
+	return scoreToElo(s, n), (scoreToElo(s+margin, n) - scoreToElo(s-margin, n)) / 2
+}
+
+func scoreToElo(s, n float64) float64 {
+	s = math.Max(math.Min(s, 1-1/(2*n)), 1/(2*n))
+	return -400 * math.Log10(1/s-1)
+}
+
+// Match plays a against b, alternating seats every game, until the test
+// accepts a hypothesis or MaxGames are played. In the returned record Player1
+// is always a and P1Wins counts a's wins regardless of seat.
+func Match(play PlayFunc, name string, a, b Engine, t SPRT, seed int64, book *Openings) Record {
+	record := Record{
+		Game:    name,
+		Player1: a.Type,
+		Config1: a.Param,
+		Player2: b.Type,
+		Config2: b.Param,
+		Seed:    seed,
+	}
+	rng := rand.New(rand.NewSource(seed))
+	for record.Decision == "" {
+		res := playSeat(play, a, b, record.Games%2 == 1, rng.Int63(), book.Get(record.Games/2))
+		record.Add(res)
+		record.LLR, record.Decision = t.Decide(record.P1Wins, record.Draws, record.P2Wins)
+		if record.Decision == "" && t.MaxGames > 0 && record.Games >= t.MaxGames {
+			record.Decision = Inconclusive
+		}
+	}
+	record.Elo, record.EloError = Elo(record.P1Wins, record.Draws, record.P2Wins)
+	return record
+}
+
+// playSeat plays one game with a in the given seat and reports the result
+// from a's point of view, as if a had been Player 1.
+func playSeat(play PlayFunc, a, b Engine, swap bool, seed int64, opening game.Opening) game.Result {
+	if !swap {
+		return play(a.Type, b.Type, a.Param, b.Param, seed, opening)
+	}
+	res := play(b.Type, a.Type, b.Param, a.Param, seed, opening)
+	if res.Winner != 0 {
+		res.Winner = 3 - res.Winner
+	}
+	res.Margin = -res.Margin
+	res.MoveTime[0], res.MoveTime[1] = res.MoveTime[1], res.MoveTime[0]
+	res.Turns[0], res.Turns[1] = res.Turns[1], res.Turns[0]
+	res.Nodes[0], res.Nodes[1] = res.Nodes[1], res.Nodes[0]
+	res.Timeouts[0], res.Timeouts[1] = res.Timeouts[1], res.Timeouts[0]
+	return res
+}
@@ -0,0 +1,111 @@
+package experiment
+
+import (
+	"github.com/damargulis/game/game"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPlaySeatSwapsEverySeatedField(t *testing.T) {
+	res := game.Result{
+		Winner:   1,
+		Margin:   3,
+		MoveTime: [2]time.Duration{1, 2},
+		Turns:    [2]int{3, 4},
+		Nodes:    [2]int64{5, 6},
+		Timeouts: [2]int{7, 8},
+	}
+	play := func(p1, p2 string, config1, config2 int, seed int64, opening game.Opening) game.Result {
+		return res
+	}
+	a, b := Engine{Type: "Computer"}, Engine{Type: "Alphabeta", Param: 1}
+	got := playSeat(play, a, b, true, 1, nil)
+	want := game.Result{
+		Winner:   2,
+		Margin:   -3,
+		MoveTime: [2]time.Duration{2, 1},
+		Turns:    [2]int{4, 3},
+		Nodes:    [2]int64{6, 5},
+		Timeouts: [2]int{8, 7},
+	}
+	if got != want {
+		t.Errorf("playSeat() = %+v, want %+v", got, want)
+	}
+}
+
+func near(got, want float64) bool {
+	return math.Abs(got-want) < 1e-6
+}
+
+func TestLLR(t *testing.T) {
+	s := SPRT{Elo0: 0, Elo1: 10, Alpha: 0.05, Beta: 0.05}
+	for _, test := range []struct {
+		wins, draws, losses int
+		want                float64
+	}{
+		{60, 20, 20, 1.1098771852651117},
+		{20, 20, 60, -1.192707907728943},
+		{50, 0, 50, -0.041415361231915915},
+		{0, 0, 0, 0},
+	} {
+		if got := s.LLR(test.wins, test.draws, test.losses); !near(got, test.want) {
+			t.Errorf("LLR(%v, %v, %v) = %v, want %v", test.wins, test.draws, test.losses, got, test.want)
+		}
+	}
+}
+
+func TestDecide(t *testing.T) {
+	s := SPRT{Elo0: 0, Elo1: 10, Alpha: 0.05, Beta: 0.05}
+	if lower, upper := s.Bounds(); !near(lower, -2.9444389791664403) || !near(upper, 2.9444389791664403) {
+		t.Errorf("Bounds() = %v, %v, want ±2.944", lower, upper)
+	}
+	for _, test := range []struct {
+		wins, draws, losses int
+		want                string
+	}{
+		{60, 20, 20, ""},
+		{180, 60, 60, AcceptH1},
+		{60, 60, 180, AcceptH0},
+		{500, 0, 500, ""},
+	} {
+		if _, got := s.Decide(test.wins, test.draws, test.losses); got != test.want {
+			t.Errorf("Decide(%v, %v, %v) = %q, want %q", test.wins, test.draws, test.losses, got, test.want)
+		}
+	}
+}
+
+func TestElo(t *testing.T) {
+	for _, test := range []struct {
+		wins, draws, losses int
+		elo, margin         float64
+	}{
+		{60, 20, 20, 147.19071411783776, 66.01463862816014},
+		{20, 20, 60, -147.19071411783779, 66.01463862816013},
+		{30, 40, 30, 0, 53.15897190457869},
+		// A clean sweep is scored as if half a game had been lost.
+		{10, 0, 0, 511.50144038113183, 0},
+		{0, 0, 0, 0, 0},
+	} {
+		elo, margin := Elo(test.wins, test.draws, test.losses)
+		if !near(elo, test.elo) || !near(margin, test.margin) {
+			t.Errorf("Elo(%v, %v, %v) = %v ± %v, want %v ± %v", test.wins, test.draws, test.losses, elo, margin, test.elo, test.margin)
+		}
+	}
+}
+
+func TestSPRTPlaysEachPairOnce(t *testing.T) {
+	engines := Engines([]Axis{{Type: "Alphabeta", From: 1, To: 3, Step: 1}})
+	want := [][2]Engine{
+		{engines[1], engines[0]},
+		{engines[2], engines[0]},
+		{engines[2], engines[1]},
+	}
+	if got := pairings(engines, &SPRT{}); !reflect.DeepEqual(got, want) {
+		t.Errorf("pairings with an SPRT = %v, want %v", got, want)
+	}
+	if got := pairings(engines, nil); len(got) != 9 {
+		t.Errorf("pairings without an SPRT = %v, want all 9 ordered pairs", got)
+	}
+}
@@ -5,24 +5,45 @@ import (
 	"github.com/damargulis/game/interfaces"
 	"github.com/damargulis/game/player"
 	"math"
+	"math/rand"
 )
 
 type Abalone struct {
-	board [9][9]string
-	p1    game.Player
-	p2    game.Player
-	pTurn bool
-	round int
+	board     [9][9]string
+	p1        game.Player
+	p2        game.Player
+	pTurn     bool
+	round     int
+	maxRounds int
 }
 
 type AbaloneMove struct {
 	startRow, startCol, endRow, endCol, moveRow, moveCol int
 }
 
-func NewAbalone(p1 string, p2 string, depth1 int, depth2 int) *Abalone {
+func (m AbaloneMove) Key() uint64 {
+	return moveKey(m.startRow, m.startCol, m.endRow, m.endCol, m.moveRow, m.moveCol)
+}
+
+// abaloneUndo is the cells a move wrote, in order, with what each held
+// before, and the turn before it. A move writes at most three cells it
+// empties, three it fills and one it pushes a marble onto.
+type abaloneUndo struct {
+	cells [7]abaloneCell
+	n     int
+	pTurn bool
+}
+
+type abaloneCell struct {
+	row, col int
+	was      string
+}
+
+func NewAbalone(p1 string, p2 string, depth1 int, depth2 int, seed int64) *Abalone {
 	g := new(Abalone)
-	g.p1 = getPlayer(p1, "Player 1", depth1)
-	g.p2 = getPlayer(p2, "Player 2", depth2)
+	rng := rand.New(rand.NewSource(seed))
+	g.p1 = getPlayer(p1, "Player 1", depth1, rng)
+	g.p2 = getPlayer(p2, "Player 2", depth2, rng)
 	g.pTurn = true
 	g.board = [9][9]string{
 		{" ", " ", " ", " ", "O", "O", ".", ".", "."},
@@ -36,6 +57,7 @@ func NewAbalone(p1 string, p2 string, depth1 int, depth2 int) *Abalone {
 		{".", ".", ".", "X", "X", " ", " ", " ", " "},
 	}
 	g.round = 0
+	g.maxRounds = defaultMaxRounds
 	return g
 }
 
@@ -43,6 +65,11 @@ func (g Abalone) GetBoardDimensions() (int, int) {
 	return len(g.board), len(g.board[0])
 }
 
+// inside reports whether row, col is on the board.
+func (g Abalone) inside(row, col int) bool {
+	return isInside(len(g.board), len(g.board[0]), row, col)
+}
+
 func (g Abalone) BoardString() string {
 	s := "----------------------\n"
 	rowLabel := 0
@@ -54,7 +81,7 @@ func (g Abalone) BoardString() string {
 		buffer++
 	}
 
-	for isInside(g, startRow, startCol) {
+	for g.inside(startRow, startCol) {
 		i := 0
 		for i < buffer {
 			s += " "
@@ -65,7 +92,7 @@ func (g Abalone) BoardString() string {
 		rowLabel++
 		curRow := startRow
 		curCol := startCol
-		for isInside(g, curRow, curCol) {
+		for g.inside(curRow, curCol) {
 			s += fmt.Sprintf("%v ", g.board[curRow][curCol])
 			curRow--
 			curCol++
@@ -87,7 +114,7 @@ func (g Abalone) BoardString() string {
 		rowLabel++
 		curRow := startRow
 		curCol := startCol
-		for isInside(g, curRow, curCol) {
+		for g.inside(curRow, curCol) {
 			s += fmt.Sprintf("%v ", g.board[curRow][curCol])
 			curRow--
 			curCol++
@@ -133,11 +160,11 @@ func (g Abalone) GetHumanInput() game.Move {
 	}
 }
 
-func (g Abalone) _getBroadMoves(i, j, rowDir, colDir, broadRowDir, broadColDir int, own, target string) []game.Move {
-	var moves []game.Move
-	if isInside(g, i+broadRowDir, j+broadColDir) &&
+func (g Abalone) _getBroadMoves(i, j, rowDir, colDir, broadRowDir, broadColDir int, own, target string) []AbaloneMove {
+	var moves []AbaloneMove
+	if g.inside(i+broadRowDir, j+broadColDir) &&
 		g.board[i+broadRowDir][j+broadColDir] == own &&
-		isInside(g, i+broadRowDir+rowDir, j+broadColDir+colDir) &&
+		g.inside(i+broadRowDir+rowDir, j+broadColDir+colDir) &&
 		g.board[i+broadRowDir+rowDir][j+broadColDir+colDir] == "." {
 		moves = append(moves, AbaloneMove{
 			startRow: i,
@@ -147,9 +174,9 @@ func (g Abalone) _getBroadMoves(i, j, rowDir, colDir, broadRowDir, broadColDir i
 			moveRow:  i + rowDir,
 			moveCol:  j + colDir,
 		})
-		if isInside(g, i+broadRowDir*2, j+broadColDir*2) &&
+		if g.inside(i+broadRowDir*2, j+broadColDir*2) &&
 			g.board[i+broadRowDir*2][j+broadColDir*2] == own &&
-			isInside(g, i+broadRowDir*2+rowDir, j+broadColDir*2) &&
+			g.inside(i+broadRowDir*2+rowDir, j+broadColDir*2) &&
 			g.board[i+broadRowDir*2+rowDir][j+broadColDir*2+colDir] == "." {
 			moves = append(moves, AbaloneMove{
 				startRow: i,
@@ -164,9 +191,9 @@ func (g Abalone) _getBroadMoves(i, j, rowDir, colDir, broadRowDir, broadColDir i
 	return moves
 }
 
-func (g Abalone) getBroadMoves(i, j, rowDir, colDir int, own, target string) []game.Move {
-	var moves []game.Move
-	if isInside(g, i+rowDir, j+colDir) && g.board[i+rowDir][j+colDir] == "." {
+func (g Abalone) getBroadMoves(i, j, rowDir, colDir int, own, target string) []AbaloneMove {
+	var moves []AbaloneMove
+	if g.inside(i+rowDir, j+colDir) && g.board[i+rowDir][j+colDir] == "." {
 		broadRowDir := rowDir
 		broadColDir := colDir
 		for k := 0; k < 2; k++ {
@@ -184,16 +211,16 @@ func (g Abalone) getBroadMoves(i, j, rowDir, colDir int, own, target string) []g
 	return moves
 }
 
-func (g Abalone) getArrowMoves(i, j, rowDir, colDir int, owns, target string) []game.Move {
-	var moves []game.Move
-	if isInside(g, i+rowDir, j+colDir) &&
+func (g Abalone) getArrowMoves(i, j, rowDir, colDir int, owns, target string) []AbaloneMove {
+	var moves []AbaloneMove
+	if g.inside(i+rowDir, j+colDir) &&
 		(g.board[i+rowDir][j+colDir] == "." ||
 			g.board[i+rowDir][j+colDir] == target) {
 		backing := 0
 		attacking := 0
 		curRow := i - rowDir
 		curCol := j - colDir
-		for isInside(g, curRow, curCol) &&
+		for g.inside(curRow, curCol) &&
 			g.board[curRow][curCol] == owns {
 			backing++
 			curRow -= rowDir
@@ -201,13 +228,13 @@ func (g Abalone) getArrowMoves(i, j, rowDir, colDir int, owns, target string) []
 		}
 		curRow = i + rowDir
 		curCol = j + colDir
-		for isInside(g, curRow, curCol) &&
+		for g.inside(curRow, curCol) &&
 			g.board[curRow][curCol] == target {
 			attacking++
 			curRow += rowDir
 			curCol += colDir
 		}
-		if isInside(g, curRow, curCol) &&
+		if g.inside(curRow, curCol) &&
 			g.board[curRow][curCol] == owns {
 			return moves
 		}
@@ -232,7 +259,11 @@ func (g Abalone) getArrowMoves(i, j, rowDir, colDir int, owns, target string) []
 }
 
 func (g Abalone) GetPossibleMoves() []game.Move {
-	var moves []game.Move
+	return boxMoves(g.PossibleMoves())
+}
+
+func (g Abalone) PossibleMoves() []AbaloneMove {
+	var moves []AbaloneMove
 	var owns, target string
 	if g.pTurn {
 		owns = "X"
@@ -263,9 +294,25 @@ func (g Abalone) GetPossibleMoves() []game.Move {
 	return moves
 }
 
+func (g Abalone) Kernel() player.Kernel {
+	return player.NewMutableKernel[Abalone, *Abalone, AbaloneMove, abaloneUndo](g)
+}
+
 func (g Abalone) MakeMove(m game.Move) game.Game {
+	g.Apply(m.(AbaloneMove))
+	return g
+}
+
+// set writes v to the cell at row, col, noting what it held in u.
+func (g *Abalone) set(u *abaloneUndo, row, col int, v string) {
+	u.cells[u.n] = abaloneCell{row: row, col: col, was: g.board[row][col]}
+	u.n++
+	g.board[row][col] = v
+}
+
+func (g *Abalone) Apply(move AbaloneMove) abaloneUndo {
+	u := abaloneUndo{pTurn: g.pTurn}
 	g.round++
-	move := m.(AbaloneMove)
 	var marbleRowDir, marbleColDir int
 	var moveRowDir, moveColDir int
 	if move.endRow-move.startRow == 0 {
@@ -294,25 +341,25 @@ func (g Abalone) MakeMove(m game.Move) game.Game {
 	curCol := move.startCol
 	for curRow != move.endRow+marbleRowDir || curCol != move.endCol+marbleColDir {
 		movingMarbles = append(movingMarbles, g.board[curRow][curCol])
-		g.board[curRow][curCol] = "."
+		g.set(&u, curRow, curCol, ".")
 		curRow += marbleRowDir
 		curCol += marbleColDir
 
 	}
 	if marbleRowDir == 0 && marbleColDir == 0 {
 		movingMarbles = append(movingMarbles, g.board[curRow][curCol])
-		g.board[curRow][curCol] = "."
+		g.set(&u, curRow, curCol, ".")
 	}
 	curRow = move.moveRow
 	curCol = move.moveCol
 	var replacing string
-	if isInside(g, curRow, curCol) {
+	if g.inside(curRow, curCol) {
 		replacing = g.board[curRow][curCol]
 	} else {
 		replacing = " "
 	}
 	for _, marble := range movingMarbles {
-		g.board[curRow][curCol] = marble
+		g.set(&u, curRow, curCol, marble)
 		curRow += marbleRowDir
 		curCol += marbleColDir
 	}
@@ -321,7 +368,7 @@ func (g Abalone) MakeMove(m game.Move) game.Game {
 		curRow = move.moveRow + moveRowDir
 		curCol = move.moveCol + moveColDir
 		var newSpot string
-		if isInside(g, curRow, curCol) {
+		if g.inside(curRow, curCol) {
 			newSpot = g.board[curRow][curCol]
 		} else {
 			newSpot = " "
@@ -329,21 +376,30 @@ func (g Abalone) MakeMove(m game.Move) game.Game {
 		for newSpot == "X" || newSpot == "O" {
 			curRow += moveRowDir
 			curCol += moveColDir
-			if isInside(g, curRow, curCol) {
+			if g.inside(curRow, curCol) {
 				newSpot = g.board[curRow][curCol]
 			} else {
 				newSpot = " "
 			}
 		}
 		if newSpot == "." {
-			g.board[curRow][curCol] = replacing
+			g.set(&u, curRow, curCol, replacing)
 		}
 	}
-	return g
+	return u
+}
+
+func (g *Abalone) Undo(u abaloneUndo) {
+	g.round--
+	g.pTurn = u.pTurn
+	for i := u.n - 1; i >= 0; i-- {
+		c := u.cells[i]
+		g.board[c.row][c.col] = c.was
+	}
 }
 
 func (g Abalone) GameOver() (bool, game.Player) {
-	if len(g.GetPossibleMoves()) == 0 {
+	if len(g.PossibleMoves()) == 0 {
 		return true, player.HumanPlayer{"DRAW"}
 	}
 	p1left := 0
@@ -357,7 +413,7 @@ func (g Abalone) GameOver() (bool, game.Player) {
 			}
 		}
 	}
-	if g.round > 500 {
+	if g.round > g.maxRounds {
 		if p1left > p2left {
 			return true, g.p1
 		} else if p2left > p1left {
@@ -378,7 +434,7 @@ func (g Abalone) _distToEdge(row, col, rowDir, colDir int) int {
 	curRow := row
 	curCol := col
 	dist := 0
-	for isInside(g, curRow, curCol) && g.board[curRow][curCol] != " " {
+	for g.inside(curRow, curCol) && g.board[curRow][curCol] != " " {
 		curRow += rowDir
 		curCol += colDir
 		dist++
@@ -443,3 +499,8 @@ func (g Abalone) CurrentScore(p game.Player) int {
 func (g Abalone) GetRound() int {
 	return g.round
 }
+
+func (g Abalone) withMaxRounds(n int) game.Game {
+	g.maxRounds = n
+	return g
+}
@@ -0,0 +1,116 @@
+package game
+
+import (
+	"github.com/damargulis/game/interfaces"
+	"github.com/damargulis/game/player"
+)
+
+const (
+	EndAdjudicatedWin  = "adjudicated_win"
+	EndAdjudicatedDraw = "adjudicated_draw"
+	EndPlyLimit        = "ply_limit"
+	EndResignation     = "resignation"
+	EndDrawAgreed      = "draw_agreed"
+)
+
+// Adjudication ends games early on the word of both engines. After each
+// move the mover's own search score is taken from it as a game.Evaluator;
+// an engine that can't report one, or missed its deadline, has the
+// position scored for it by a Depth ply search instead. A game is given to
+// a player once both engines' latest scores have favoured them by at least
+// WinEval for WinMoves moves by each side in a row, and drawn once both
+// have stayed within DrawEval of even for DrawMoves moves by each side. A
+// game reaching MaxPlies is drawn. Zero turns a rule off.
+type Adjudication struct {
+	Depth     int
+	WinEval   int
+	WinMoves  int
+	DrawEval  int
+	DrawMoves int
+	MaxPlies  int
+}
+
+// defaultMaxRounds is how many moves a roundLimited game allows unless a
+// game's MaxPlies calls for more.
+const defaultMaxRounds = 500
+
+// roundLimited is a game that ends itself after maxRounds moves, so that
+// neither it nor a search's playout of it can go on forever. Play moves the
+// limit past MaxPlies so that the ply limit is what ends the game.
+type roundLimited interface {
+	withMaxRounds(n int) game.Game
+}
+
+type adjudicator struct {
+	Adjudication
+	// evals is each seat's latest score for Player 1, once it has moved.
+	evals      [2]int
+	moved      [2]bool
+	leader     int
+	winStreak  int
+	drawStreak int
+}
+
+// forPlayer1 turns a score for the player in seat s into one for Player 1.
+func forPlayer1(eval, s int) int {
+	if s == 0 {
+		return eval
+	} else if eval == player.MinInt {
+		return player.MaxInt
+	}
+	return -eval
+}
+
+// check looks at the position after plies moves, the last played from seat
+// s by e if it can say how it scored the move, and returns the winning
+// seat, 0 for a draw, with the reason when the game should end here.
+func (a *adjudicator) check(g game.Game, s int, e game.Evaluator, plies int) (int, string, bool) {
+	if a.WinMoves > 0 || a.DrawMoves > 0 {
+		eval, ok := 0, false
+		if e != nil {
+			eval, ok = e.LastEval()
+		}
+		if ok {
+			eval = forPlayer1(eval, s)
+		} else {
+			eval = forPlayer1(Evaluate(g, a.Depth), seat(g.GetPlayerTurn()))
+		}
+		a.evals[s], a.moved[s] = eval, true
+		leader, drawn := 0, false
+		if a.moved[0] && a.moved[1] {
+			lo, hi := a.evals[0], a.evals[1]
+			if lo > hi {
+				lo, hi = hi, lo
+			}
+			if lo >= a.WinEval {
+				leader = 1
+			} else if hi <= -a.WinEval {
+				leader = 2
+			}
+			drawn = lo >= -a.DrawEval && hi <= a.DrawEval
+		}
+		if leader != 0 && leader == a.leader {
+			a.winStreak++
+		} else if leader != 0 {
+			a.winStreak = 1
+		} else {
+			a.winStreak = 0
+		}
+		a.leader = leader
+		if drawn {
+			a.drawStreak++
+		} else {
+			a.drawStreak = 0
+		}
+		if a.WinMoves > 0 && a.winStreak >= 2*a.WinMoves {
+			return a.leader, EndAdjudicatedWin, true
+		}
+		if a.DrawMoves > 0 && a.drawStreak >= 2*a.DrawMoves {
+			return 0, EndAdjudicatedDraw, true
+		}
+	}
+	if a.MaxPlies > 0 && plies >= a.MaxPlies {
+		return 0, EndPlyLimit, true
+	}
+	return 0, "", false
+}
@@ -0,0 +1,77 @@
+package game
+
+import "testing"
+
+// evaluator reports the same score for itself after every move.
+type evaluator int
+
+func (e evaluator) LastEval() (int, bool) {
+	return int(e), true
+}
+
+func TestAdjudicationWaitsForBothEnginesToAgree(t *testing.T) {
+	g := NewTicTacToe("Computer", "Computer", 0, 0, 1)
+	rules := Adjudication{WinEval: 100, WinMoves: 2}
+	for _, test := range []struct {
+		name   string
+		p1, p2 evaluator
+		winner int
+		plies  int
+	}{
+		{"both favour Player 1", 200, -150, 1, 5},
+		{"both favour Player 2", -300, 100, 2, 5},
+		{"Player 2 disagrees", 200, 150, 0, 0},
+		{"Player 2 isn't sure", 200, -50, 0, 0},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			a := adjudicator{Adjudication: rules}
+			for plies := 1; plies <= 10; plies++ {
+				e := test.p1
+				if plies%2 == 0 {
+					e = test.p2
+				}
+				if w, reason, ok := a.check(g, 1-plies%2, e, plies); ok {
+					if w != test.winner || reason != EndAdjudicatedWin || plies != test.plies {
+						t.Errorf("adjudicated %v to %v after %v plies, want a win for %v after %v", reason, w, plies, test.winner, test.plies)
+					}
+					return
+				}
+			}
+			if test.winner != 0 {
+				t.Errorf("not adjudicated, want a win for %v after %v plies", test.winner, test.plies)
+			}
+		})
+	}
+}
+
+func TestAdjudicationDrawsOnTheEnginesWord(t *testing.T) {
+	// Searched to the end, tic tac toe is a draw from the first move, and
+	// both engines say so from their first moves on.
+	g := NewTicTacToe("Minimax", "Minimax", 100, 100, 1)
+	res := PlayWith(g, false, Rules{Adjudication: Adjudication{DrawMoves: 1}})
+	if res.Reason != EndAdjudicatedDraw || res.Winner != 0 || res.Moves != 3 {
+		t.Errorf("game ended by %q won by %v after %v moves, want %q after 3", res.Reason, res.Winner, res.Moves, EndAdjudicatedDraw)
+	}
+}
+
+func TestAdjudicationScoresForEnginesWithoutAnEval(t *testing.T) {
+	// Player 1 is to move with two in a row, and neither engine can say so.
+	g := NewTicTacToe("Computer", "Computer", 0, 0, 1)
+	g.board[0][0], g.board[0][1] = "X", "X"
+	a := adjudicator{Adjudication: Adjudication{Depth: 1, WinEval: 1, WinMoves: 1}}
+	for plies := 1; plies <= 3; plies++ {
+		w, reason, ok := a.check(g, 1-plies%2, nil, plies)
+		if ok != (plies == 3) || ok && (w != 1 || reason != EndAdjudicatedWin) {
+			t.Fatalf("after %v plies check() = %v, %q, %v, want a win for Player 1 after 3", plies, w, reason, ok)
+		}
+	}
+}
+
+func TestMaxPliesOutlastsTheGamesOwnLimit(t *testing.T) {
+	g := NewCheckers("Computer", "Computer", 0, 0, 1)
+	g.round = defaultMaxRounds - 5
+	res := PlayWith(g, false, Rules{Adjudication: Adjudication{MaxPlies: 20}})
+	if res.Reason != EndPlyLimit || res.Moves != 20 {
+		t.Errorf("game ended by %q after %v moves, want %q after 20", res.Reason, res.Moves, EndPlyLimit)
+	}
+}
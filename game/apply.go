@@ -0,0 +1,47 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"github.com/damargulis/game/interfaces"
+	"reflect"
+)
+
+const EndIllegalMove = "illegal_move"
+
+var (
+	ErrGameOver      = errors.New("the game is over")
+	ErrWrongMoveType = errors.New("wrong move type")
+	ErrIllegalMove   = errors.New("illegal move")
+)
+
+// Apply is MakeMove for moves that may not be legal, from players and
+// other programs, returning an error instead of corrupting g. Searchers
+// that only play moves from GetPossibleMoves keep to MakeMove.
+func Apply(g game.Game, m game.Move) (game.Game, error) {
+	if err := Check(g, m); err != nil {
+		return g, err
+	}
+	return g.MakeMove(m), nil
+}
+
+// Check reports whether m can be played in g, asking g itself if it is a
+// MoveChecker and otherwise looking for m among its possible moves.
+func Check(g game.Game, m game.Move) error {
+	if over, _ := g.GameOver(); over {
+		return ErrGameOver
+	}
+	if c, ok := g.(game.MoveChecker); ok {
+		return c.CheckMove(m)
+	}
+	moves := g.GetPossibleMoves()
+	if len(moves) > 0 && reflect.TypeOf(m) != reflect.TypeOf(moves[0]) {
+		return fmt.Errorf("%w %T, want %T", ErrWrongMoveType, m, moves[0])
+	}
+	for _, legal := range moves {
+		if game.SameMove(legal, m) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w %+v", ErrIllegalMove, m)
+}
@@ -0,0 +1,24 @@
+package game
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckRejectsMovesOffTheBoard(t *testing.T) {
+	g := NewReversi("Computer", "Computer", 0, 0, 1)
+	legal := ReversiMove{row: 2, col: 4}
+	if err := Check(g, legal); err != nil {
+		t.Fatalf("Check(%+v) = %v, want nil", legal, err)
+	}
+	off := ReversiMove{row: 258, col: 4}
+	if off.Key() != legal.Key() {
+		t.Fatalf("keys of %+v and %+v differ; pick moves that share a key", off, legal)
+	}
+	if err := Check(g, off); !errors.Is(err, ErrIllegalMove) {
+		t.Errorf("Check(%+v) = %v, want %v", off, err, ErrIllegalMove)
+	}
+	if _, err := Apply(g, off); !errors.Is(err, ErrIllegalMove) {
+		t.Errorf("Apply(%+v) = %v, want %v", off, err, ErrIllegalMove)
+	}
+}
@@ -0,0 +1,151 @@
+package game
+
+import (
+	"fmt"
+	"github.com/damargulis/game/interfaces"
+	"github.com/damargulis/game/player"
+	"math/bits"
+	"math/rand"
+)
+
+// BitConnect4 is Connect4 with each side's discs kept as a bit for each
+// cell, row by row from the top, so four in a row is found by shifting.
+type BitConnect4 struct {
+	x, o  uint64
+	p1    game.Player
+	p2    game.Player
+	pTurn bool
+	round int
+}
+
+func NewBitConnect4(p1 string, p2 string, depth1 int, depth2 int, seed int64) *BitConnect4 {
+	c := new(BitConnect4)
+	rng := rand.New(rand.NewSource(seed))
+	c.p1 = getPlayer(p1, "Player 1", depth1, rng)
+	c.p2 = getPlayer(p2, "Player 2", depth2, rng)
+	c.pTurn = true
+	return c
+}
+
+func (g BitConnect4) Kernel() player.Kernel {
+	return player.NewKernel[BitConnect4, Connect4Move](g)
+}
+
+func (g BitConnect4) GetBoardDimensions() (int, int) {
+	return 8, 8
+}
+
+func (g BitConnect4) BoardString() string {
+	s := "-----------------\n"
+	for i := 0; i < 8; i++ {
+		s += fmt.Sprintf("%v ", i)
+		for j := 0; j < 8; j++ {
+			s += bitCell(g.x, g.o, i*8+j)
+			s += " "
+		}
+		s += "\n"
+	}
+	s += "  0 1 2 3 4 5 6 7\n"
+	s += "-----------------"
+	return s
+}
+
+func (g BitConnect4) GetPlayerTurn() game.Player {
+	if g.pTurn {
+		return g.p1
+	} else {
+		return g.p2
+	}
+}
+
+func (g BitConnect4) GetHumanInput() game.Move {
+	col := readInts("Column to move in: ")
+	return Connect4Move{col: col[0]}
+}
+
+func (g BitConnect4) GetPossibleMoves() []game.Move {
+	return boxMoves(g.PossibleMoves())
+}
+
+func (g BitConnect4) PossibleMoves() []Connect4Move {
+	return g.AppendMoves(nil)
+}
+
+func (g BitConnect4) AppendMoves(moves []Connect4Move) []Connect4Move {
+	top := ^(g.x | g.o) & bitRow
+	for ; top != 0; top &= top - 1 {
+		moves = append(moves, Connect4Move{col: bits.TrailingZeros64(top)})
+	}
+	return moves
+}
+
+func (g BitConnect4) RandomMove(r *rand.Rand) Connect4Move {
+	top := ^(g.x | g.o) & bitRow
+	return Connect4Move{col: nthBit(top, r.Intn(bits.OnesCount64(top)))}
+}
+
+func (g BitConnect4) CheckMove(m game.Move) error {
+	move, ok := m.(Connect4Move)
+	if !ok {
+		return fmt.Errorf("%w %T, want %T", ErrWrongMoveType, m, Connect4Move{})
+	}
+	if !isInside(8, 8, 0, move.col) || (g.x|g.o)&(1<<uint(move.col)) != 0 {
+		return fmt.Errorf("%w %+v", ErrIllegalMove, m)
+	}
+	return nil
+}
+
+func (g BitConnect4) MakeMove(m game.Move) game.Game {
+	return g.Play(m.(Connect4Move))
+}
+
+func (g BitConnect4) Play(move Connect4Move) BitConnect4 {
+	g.round++
+	// The lowest empty cell in the column is the last before its first
+	// disc, or the bottom row in an empty column.
+	column := (g.x | g.o) & (bitColumn << uint(move.col))
+	bit := uint64(1) << uint(56+move.col)
+	if column != 0 {
+		bit = uint64(1) << uint(bits.TrailingZeros64(column)-8)
+	}
+	if g.pTurn {
+		g.x |= bit
+	} else {
+		g.o |= bit
+	}
+	g.pTurn = !g.pTurn
+	return g
+}
+
+// fourInARow reports whether discs have four in a line in any direction.
+func (g BitConnect4) fourInARow(discs uint64) bool {
+	for _, d := range bitDirections[:4] {
+		run := discs
+		for i := 0; i < 3; i++ {
+			run &= d.shift(run)
+		}
+		if run != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (g BitConnect4) GameOver() (bool, game.Player) {
+	if g.fourInARow(g.x) {
+		return true, g.p1
+	} else if g.fourInARow(g.o) {
+		return true, g.p2
+	} else if g.x|g.o != ^uint64(0) {
+		return false, player.ComputerPlayer{}
+	}
+	return true, player.HumanPlayer{Name: "DRAW"}
+}
+
+func (g BitConnect4) CurrentScore(p game.Player) int {
+	return 0
+}
+
+func (g BitConnect4) GetRound() int {
+	return g.round
+}
@@ -0,0 +1,174 @@
+package game
+
+import (
+	"fmt"
+	"github.com/damargulis/game/interfaces"
+	"github.com/damargulis/game/player"
+	"math/bits"
+	"math/rand"
+)
+
+// BitReversi is Reversi with each side's discs kept as a bit for each cell,
+// so moves and flips are found for every disc at once by shifting.
+type BitReversi struct {
+	x, o  uint64
+	p1    game.Player
+	p2    game.Player
+	pTurn bool
+	round int
+}
+
+func NewBitReversi(p1 string, p2 string, depth1 int, depth2 int, seed int64) *BitReversi {
+	r := new(BitReversi)
+	rng := rand.New(rand.NewSource(seed))
+	r.p1 = getPlayer(p1, "Player 1", depth1, rng)
+	r.p2 = getPlayer(p2, "Player 2", depth2, rng)
+	r.pTurn = true
+	r.x = 1<<(3*8+3) | 1<<(4*8+4)
+	r.o = 1<<(3*8+4) | 1<<(4*8+3)
+	return r
+}
+
+func (g BitReversi) Kernel() player.Kernel {
+	return player.NewKernel[BitReversi, ReversiMove](g)
+}
+
+func (g BitReversi) GetBoardDimensions() (int, int) {
+	return 8, 8
+}
+
+func (g BitReversi) BoardString() string {
+	s := "-----------------\n"
+	s += "  0 1 2 3 4 5 6 7\n"
+	for i := 0; i < 8; i++ {
+		s += fmt.Sprintf("%v ", i)
+		for j := 0; j < 8; j++ {
+			s += bitCell(g.x, g.o, i*8+j)
+			s += " "
+		}
+		s += "\n"
+	}
+	s += "  0 1 2 3 4 5 6 7\n"
+	s += "-----------------"
+	return s
+}
+
+func (g BitReversi) GetPlayerTurn() game.Player {
+	if g.pTurn {
+		return g.p1
+	} else {
+		return g.p2
+	}
+}
+
+func (g BitReversi) GetHumanInput() game.Move {
+	spot := readInts("Spot to place: ")
+	return ReversiMove{row: spot[0], col: spot[1]}
+}
+
+// sides is the discs of the player to move and of their opponent.
+func (g BitReversi) sides() (uint64, uint64) {
+	if g.pTurn {
+		return g.x, g.o
+	}
+	return g.o, g.x
+}
+
+// moves is a bit for each empty cell that would outflank a line of the
+// opponent's discs.
+func (g BitReversi) moves() uint64 {
+	own, opp := g.sides()
+	empty := ^(own | opp)
+	var moves uint64
+	for _, d := range bitDirections {
+		line := d.shift(own) & opp
+		for i := 0; i < 5; i++ {
+			line |= d.shift(line) & opp
+		}
+		moves |= d.shift(line) & empty
+	}
+	return moves
+}
+
+func (g BitReversi) GetPossibleMoves() []game.Move {
+	return boxMoves(g.PossibleMoves())
+}
+
+func (g BitReversi) PossibleMoves() []ReversiMove {
+	return g.AppendMoves(nil)
+}
+
+func (g BitReversi) AppendMoves(moves []ReversiMove) []ReversiMove {
+	for m := g.moves(); m != 0; m &= m - 1 {
+		i := bits.TrailingZeros64(m)
+		moves = append(moves, ReversiMove{row: i / 8, col: i % 8})
+	}
+	return moves
+}
+
+func (g BitReversi) RandomMove(r *rand.Rand) ReversiMove {
+	moves := g.moves()
+	i := nthBit(moves, r.Intn(bits.OnesCount64(moves)))
+	return ReversiMove{row: i / 8, col: i % 8}
+}
+
+func (g BitReversi) MakeMove(m game.Move) game.Game {
+	return g.Play(m.(ReversiMove))
+}
+
+func (g BitReversi) Play(move ReversiMove) BitReversi {
+	g.round++
+	own, opp := g.sides()
+	bit := uint64(1) << uint(move.row*8+move.col)
+	var flipped uint64
+	for _, d := range bitDirections {
+		var line uint64
+		next := d.shift(bit)
+		for next&opp != 0 {
+			line |= next
+			next = d.shift(next)
+		}
+		if next&own != 0 {
+			flipped |= line
+		}
+	}
+	own |= bit | flipped
+	opp &^= flipped
+	if g.pTurn {
+		g.x, g.o = own, opp
+	} else {
+		g.o, g.x = own, opp
+	}
+	g.pTurn = !g.pTurn
+	if g.moves() == 0 {
+		g.pTurn = !g.pTurn
+	}
+	return g
+}
+
+func (g BitReversi) GameOver() (bool, game.Player) {
+	if g.moves() != 0 {
+		return false, player.ComputerPlayer{}
+	}
+	score := g.CurrentScore(g.p1)
+	if score > 0 {
+		return true, g.p1
+	} else if score < 0 {
+		return true, g.p2
+	} else {
+		return true, player.HumanPlayer{Name: "DRAW"}
+	}
+}
+
+func (g BitReversi) CurrentScore(p game.Player) int {
+	score := bits.OnesCount64(g.x) - bits.OnesCount64(g.o)
+	if p == g.p1 {
+		return score
+	} else {
+		return -1 * score
+	}
+}
+
+func (g BitReversi) GetRound() int {
+	return g.round
+}
@@ -0,0 +1,147 @@
+package game
+
+import (
+	"fmt"
+	"github.com/damargulis/game/interfaces"
+	"github.com/damargulis/game/player"
+	"math/bits"
+	"math/rand"
+)
+
+// BitTicTacToe is TicTacToe with each side's marks kept as a bit for each
+// cell, row by row, so a line is a mask.
+type BitTicTacToe struct {
+	x, o  uint16
+	p1    game.Player
+	p2    game.Player
+	pTurn bool
+	round int
+}
+
+const bitTicTacToeFull = 1<<9 - 1
+
+var bitTicTacToeLines = [8]uint16{
+	0x007, 0x038, 0x1c0, // rows
+	0x049, 0x092, 0x124, // columns
+	0x111, 0x054, // diagonals
+}
+
+func NewBitTicTacToe(p1 string, p2 string, depth1 int, depth2 int, seed int64) *BitTicTacToe {
+	g := new(BitTicTacToe)
+	rng := rand.New(rand.NewSource(seed))
+	g.p1 = getPlayer(p1, "Player 1", depth1, rng)
+	g.p2 = getPlayer(p2, "Player 2", depth2, rng)
+	g.pTurn = true
+	return g
+}
+
+func (g BitTicTacToe) Kernel() player.Kernel {
+	return player.NewKernel[BitTicTacToe, TicTacToeMove](g)
+}
+
+func (g BitTicTacToe) GetBoardDimensions() (int, int) {
+	return 3, 3
+}
+
+func (g BitTicTacToe) GetHumanInput() game.Move {
+	spot := readInts("Spot to place: ")
+	return TicTacToeMove{row: spot[0], col: spot[1]}
+}
+
+func (g BitTicTacToe) won(marks uint16) bool {
+	for _, line := range bitTicTacToeLines {
+		if marks&line == line {
+			return true
+		}
+	}
+	return false
+}
+
+func (g BitTicTacToe) GameOver() (bool, game.Player) {
+	if g.won(g.x) {
+		return true, g.p1
+	} else if g.won(g.o) {
+		return true, g.p2
+	} else if g.x|g.o != bitTicTacToeFull {
+		return false, player.ComputerPlayer{}
+	}
+	return true, player.HumanPlayer{Name: "DRAW"}
+}
+
+func (g BitTicTacToe) MakeMove(m game.Move) game.Game {
+	return g.Play(m.(TicTacToeMove))
+}
+
+func (g BitTicTacToe) Play(move TicTacToeMove) BitTicTacToe {
+	g.round++
+	bit := uint16(1) << uint(move.row*3+move.col)
+	if g.pTurn {
+		g.x |= bit
+	} else {
+		g.o |= bit
+	}
+	g.pTurn = !g.pTurn
+	return g
+}
+
+func (g BitTicTacToe) CheckMove(m game.Move) error {
+	move, ok := m.(TicTacToeMove)
+	if !ok {
+		return fmt.Errorf("%w %T, want %T", ErrWrongMoveType, m, TicTacToeMove{})
+	}
+	if !isInside(3, 3, move.row, move.col) || (g.x|g.o)&(1<<uint(move.row*3+move.col)) != 0 {
+		return fmt.Errorf("%w %+v", ErrIllegalMove, m)
+	}
+	return nil
+}
+
+func (g BitTicTacToe) GetPossibleMoves() []game.Move {
+	return boxMoves(g.PossibleMoves())
+}
+
+func (g BitTicTacToe) PossibleMoves() []TicTacToeMove {
+	return g.AppendMoves(nil)
+}
+
+func (g BitTicTacToe) AppendMoves(moves []TicTacToeMove) []TicTacToeMove {
+	for empty, i := ^(g.x|g.o)&bitTicTacToeFull, 0; empty != 0; empty, i = empty>>1, i+1 {
+		if empty&1 != 0 {
+			moves = append(moves, TicTacToeMove{row: i / 3, col: i % 3})
+		}
+	}
+	return moves
+}
+
+func (g BitTicTacToe) RandomMove(r *rand.Rand) TicTacToeMove {
+	empty := uint64(^(g.x | g.o) & bitTicTacToeFull)
+	i := nthBit(empty, r.Intn(bits.OnesCount64(empty)))
+	return TicTacToeMove{row: i / 3, col: i % 3}
+}
+
+func (g BitTicTacToe) CurrentScore(p game.Player) int {
+	return 0
+}
+
+func (g BitTicTacToe) GetPlayerTurn() game.Player {
+	if g.pTurn {
+		return g.p1
+	} else {
+		return g.p2
+	}
+}
+
+func (g BitTicTacToe) BoardString() string {
+	s := "---\n"
+	for i := 0; i < 9; i++ {
+		s += bitCell(uint64(g.x), uint64(g.o), i)
+		if i%3 == 2 {
+			s += "\n"
+		}
+	}
+	s += "---"
+	return s
+}
+
+func (g BitTicTacToe) GetRound() int {
+	return g.round
+}
@@ -0,0 +1,66 @@
+package game
+
+import "math/bits"
+
+// The bitboard games keep one bit for each cell of an 8x8 board, row by row
+// from the top left, so cell row, col is bit row*8+col.
+const (
+	bitRow    uint64 = 0xff
+	bitColumn uint64 = 0x0101010101010101
+)
+
+// bitDirection is a step across the board as a shift of every bit at once.
+// Bits stepping off the left or right edge are dropped by mask rather than
+// wrapping onto the next row.
+type bitDirection struct {
+	delta int
+	mask  uint64
+}
+
+func newBitDirection(rowDir, colDir int) bitDirection {
+	mask := ^uint64(0)
+	if colDir == 1 {
+		mask = ^bitColumn
+	} else if colDir == -1 {
+		mask = ^(bitColumn << 7)
+	}
+	return bitDirection{delta: rowDir*8 + colDir, mask: mask}
+}
+
+func (d bitDirection) shift(b uint64) uint64 {
+	if d.delta > 0 {
+		return b << uint(d.delta) & d.mask
+	}
+	return b >> uint(-d.delta) & d.mask
+}
+
+// bitDirections are the eight directions, the first four each along a
+// different line.
+var bitDirections = [8]bitDirection{
+	newBitDirection(0, 1),
+	newBitDirection(1, 0),
+	newBitDirection(1, 1),
+	newBitDirection(1, -1),
+	newBitDirection(0, -1),
+	newBitDirection(-1, 0),
+	newBitDirection(-1, -1),
+	newBitDirection(-1, 1),
+}
+
+// nthBit is the index of the nth lowest set bit of b.
+func nthBit(b uint64, n int) int {
+	for ; n > 0; n-- {
+		b &= b - 1
+	}
+	return bits.TrailingZeros64(b)
+}
+
+// bitCell is how the board strings show cell i of a bitboard game.
+func bitCell(x, o uint64, i int) string {
+	if x&(1<<uint(i)) != 0 {
+		return "X"
+	} else if o&(1<<uint(i)) != 0 {
+		return "O"
+	}
+	return "."
+}
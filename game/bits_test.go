@@ -0,0 +1,82 @@
+package game
+
+import (
+	"github.com/damargulis/game/interfaces"
+	"math/rand"
+	"testing"
+)
+
+// bitPairs are the bitboard games with the string games they must agree
+// with over as many random games as the string game can play quickly.
+var bitPairs = []struct {
+	name     string
+	strings  game.Game
+	bitboard game.Game
+	games    int
+}{
+	{"tictactoe", NewTicTacToe("Computer", "Computer", 0, 0, 1), NewBitTicTacToe("Computer", "Computer", 0, 0, 1), 3000},
+	{"connect4", NewConnect4("Computer", "Computer", 0, 0, 1), NewBitConnect4("Computer", "Computer", 0, 0, 1), 1000},
+	{"reversi", NewReversi("Computer", "Computer", 0, 0, 1), NewBitReversi("Computer", "Computer", 0, 0, 1), 100},
+}
+
+func TestBitboardsMatchStrings(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, pair := range bitPairs {
+		games := pair.games
+		if testing.Short() {
+			games /= 10
+		}
+		for n := 0; n < games; n++ {
+			a, b := pair.strings, pair.bitboard
+			for ply := 0; ; ply++ {
+				overA, winnerA := a.GameOver()
+				overB, winnerB := b.GameOver()
+				if overA != overB || winnerA.GetName() != winnerB.GetName() {
+					t.Fatalf("%v game %v ply %v: game over %v %v, bitboard %v %v", pair.name, n, ply, overA, winnerA.GetName(), overB, winnerB.GetName())
+				}
+				if a.GetRound() != b.GetRound() || a.GetPlayerTurn().GetName() != b.GetPlayerTurn().GetName() {
+					t.Fatalf("%v game %v ply %v: round %v turn %v, bitboard %v %v", pair.name, n, ply, a.GetRound(), a.GetPlayerTurn().GetName(), b.GetRound(), b.GetPlayerTurn().GetName())
+				}
+				if a.BoardString() != b.BoardString() || a.CurrentScore(a.GetPlayerTurn()) != b.CurrentScore(b.GetPlayerTurn()) {
+					t.Fatalf("%v game %v ply %v: board\n%v\nbitboard\n%v", pair.name, n, ply, a.BoardString(), b.BoardString())
+				}
+				movesA, movesB := a.GetPossibleMoves(), b.GetPossibleMoves()
+				if overA {
+					break
+				}
+				if len(movesA) != len(movesB) {
+					t.Fatalf("%v game %v ply %v: moves %v, bitboard %v", pair.name, n, ply, movesA, movesB)
+				}
+				for i := range movesA {
+					if movesA[i] != movesB[i] {
+						t.Fatalf("%v game %v ply %v: moves %v, bitboard %v", pair.name, n, ply, movesA, movesB)
+					}
+				}
+				m := movesA[r.Intn(len(movesA))]
+				a, b = a.MakeMove(m), b.MakeMove(m)
+			}
+		}
+	}
+}
+
+func benchmarkPlayouts(b *testing.B, g game.Game) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < b.N; i++ {
+		pos := g
+		for over, _ := pos.GameOver(); !over; over, _ = pos.GameOver() {
+			moves := pos.GetPossibleMoves()
+			pos = pos.MakeMove(moves[r.Intn(len(moves))])
+		}
+	}
+}
+
+func BenchmarkPlayouts(b *testing.B) {
+	for _, pair := range bitPairs {
+		b.Run(pair.name+"/strings", func(b *testing.B) {
+			benchmarkPlayouts(b, pair.strings)
+		})
+		b.Run(pair.name+"/bitboard", func(b *testing.B) {
+			benchmarkPlayouts(b, pair.bitboard)
+		})
+	}
+}
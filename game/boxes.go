@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/damargulis/game/interfaces"
 	"github.com/damargulis/game/player"
+	"math/rand"
 )
 
 type Boxes struct {
@@ -18,10 +19,26 @@ type BoxesMove struct {
 	row, col int
 }
 
+func (m BoxesMove) Key() uint64 {
+	return moveKey(m.row, m.col)
+}
+
+// boxesUndo is a line drawn and whose turn it was. Any box next to the line
+// was claimed by drawing it.
+type boxesUndo struct {
+	move  BoxesMove
+	pTurn bool
+}
+
 func (g Boxes) GetBoardDimensions() (int, int) {
 	return len(g.board), len(g.board[0])
 }
 
+// inside reports whether row, col is on the board.
+func (g Boxes) inside(row, col int) bool {
+	return isInside(len(g.board), len(g.board[0]), row, col)
+}
+
 func (g Boxes) BoardString() string {
 	s := "*******************\n"
 	s += "  0 1 2 3 4 5 6 7 8\n"
@@ -55,8 +72,19 @@ func (g Boxes) GetHumanInput() game.Move {
 	}
 }
 
+func (g Boxes) Kernel() player.Kernel {
+	return player.NewMutableKernel[Boxes, *Boxes, BoxesMove, boxesUndo](g)
+}
+
 func (g Boxes) GetPossibleMoves() []game.Move {
-	var moves []game.Move
+	return boxMoves(g.PossibleMoves())
+}
+
+func (g Boxes) PossibleMoves() []BoxesMove {
+	return g.AppendMoves(nil)
+}
+
+func (g Boxes) AppendMoves(moves []BoxesMove) []BoxesMove {
 	for i, row := range g.board {
 		for j, spot := range row {
 			if (i+j)%2 != 0 && spot == " " {
@@ -71,10 +99,10 @@ func (g Boxes) GetPossibleMoves() []game.Move {
 }
 
 func (g Boxes) checkSpot(i, j int) bool {
-	return isInside(g, i+1, j) &&
-		isInside(g, i-1, j) &&
-		isInside(g, i, j+1) &&
-		isInside(g, i, j-1) &&
+	return g.inside(i+1, j) &&
+		g.inside(i-1, j) &&
+		g.inside(i, j+1) &&
+		g.inside(i, j-1) &&
 		g.board[i+1][j] != " " &&
 		g.board[i-1][j] != " " &&
 		g.board[i][j+1] != " " &&
@@ -82,9 +110,14 @@ func (g Boxes) checkSpot(i, j int) bool {
 }
 
 func (g Boxes) MakeMove(move game.Move) game.Game {
+	g.Apply(move.(BoxesMove))
+	return g
+}
+
+func (g *Boxes) Apply(m BoxesMove) boxesUndo {
+	u := boxesUndo{move: m, pTurn: g.pTurn}
 	g.round++
 	didClaim := false
-	m := move.(BoxesMove)
 	var own string
 	if g.pTurn {
 		own = "X"
@@ -115,11 +148,31 @@ func (g Boxes) MakeMove(move game.Move) game.Game {
 	if !didClaim {
 		g.pTurn = !g.pTurn
 	}
-	return g
+	return u
+}
+
+func (g *Boxes) Undo(u boxesUndo) {
+	g.round--
+	g.pTurn = u.pTurn
+	m := u.move
+	g.board[m.row][m.col] = " "
+	if m.row%2 == 0 {
+		for _, i := range []int{m.row - 1, m.row + 1} {
+			if g.inside(i, m.col) {
+				g.board[i][m.col] = " "
+			}
+		}
+	} else {
+		for _, j := range []int{m.col - 1, m.col + 1} {
+			if g.inside(m.row, j) {
+				g.board[m.row][j] = " "
+			}
+		}
+	}
 }
 
 func (g Boxes) GameOver() (bool, game.Player) {
-	possibleMoves := g.GetPossibleMoves()
+	possibleMoves := g.PossibleMoves()
 	if len(possibleMoves) == 0 {
 		score := g.CurrentScore(g.p1)
 		if score > 0 {
@@ -152,11 +205,12 @@ func (g Boxes) CurrentScore(p game.Player) int {
 	}
 }
 
-func NewBoxes(p1 string, p2 string, depth1 int, depth2 int) *Boxes {
+func NewBoxes(p1 string, p2 string, depth1 int, depth2 int, seed int64) *Boxes {
 	g := new(Boxes)
 	g.round = 0
-	g.p1 = getPlayer(p1, "Player 1", depth1)
-	g.p2 = getPlayer(p2, "Player 2", depth2)
+	rng := rand.New(rand.NewSource(seed))
+	g.p1 = getPlayer(p1, "Player 1", depth1, rng)
+	g.p2 = getPlayer(p2, "Player 2", depth2, rng)
 	g.pTurn = true
 	g.board = [9][9]string{
 		{".", " ", ".", " ", ".", " ", ".", " ", "."},
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/damargulis/game/interfaces"
 	"github.com/damargulis/game/player"
+	"math/rand"
 )
 
 type Checkers struct {
@@ -13,16 +14,38 @@ type Checkers struct {
 	pTurn, didJustJump bool
 	jumpRow, jumpCol   int
 	round              int
+	maxRounds          int
 }
 
 type CheckersMove struct {
 	row1, col1, row2, col2 int
 }
 
+func (m CheckersMove) Key() uint64 {
+	return moveKey(m.row1, m.col1, m.row2, m.col2)
+}
+
+// checkersUndo is a move with the pieces it moved and took, and the state
+// of the turn before it.
+type checkersUndo struct {
+	move        CheckersMove
+	piece       string
+	captured    string
+	pTurn       bool
+	didJustJump bool
+	jumpRow     int
+	jumpCol     int
+}
+
 func (g Checkers) GetBoardDimensions() (int, int) {
 	return len(g.board), len(g.board[0])
 }
 
+// inside reports whether row, col is on the board.
+func (g Checkers) inside(row, col int) bool {
+	return isInside(len(g.board), len(g.board[0]), row, col)
+}
+
 func (g Checkers) GetHumanInput() game.Move {
 	spot1 := readInts("Peice to move: ")
 	spot2 := readInts("Move to: ")
@@ -46,18 +69,19 @@ func (g Checkers) GameOver() (bool, game.Player) {
 	} else if !p2Alive {
 		return true, g.p1
 	} else {
-		moves := g.GetPossibleMoves()
-		if len(moves) == 0 || g.round > 500 {
+		moves := g.PossibleMoves()
+		if len(moves) == 0 || g.round > g.maxRounds {
 			return true, player.HumanPlayer{"DRAW"}
 		}
 		return false, player.ComputerPlayer{}
 	}
 }
 
-func NewCheckers(p1 string, p2 string, depth1 int, depth2 int) *Checkers {
+func NewCheckers(p1 string, p2 string, depth1 int, depth2 int, seed int64) *Checkers {
 	c := new(Checkers)
-	c.p1 = getPlayer(p1, "Player 1", depth1)
-	c.p2 = getPlayer(p2, "Player 2", depth2)
+	rng := rand.New(rand.NewSource(seed))
+	c.p1 = getPlayer(p1, "Player 1", depth1, rng)
+	c.p2 = getPlayer(p2, "Player 2", depth2, rng)
 	c.pTurn = true
 	c.board = [8][8]string{
 		{".", "o", ".", "o", ".", "o", ".", "o"},
@@ -71,17 +95,35 @@ func NewCheckers(p1 string, p2 string, depth1 int, depth2 int) *Checkers {
 	}
 	c.didJustJump = false
 	c.round = 0
+	c.maxRounds = defaultMaxRounds
 	return c
 }
 
+func (g Checkers) Kernel() player.Kernel {
+	return player.NewMutableKernel[Checkers, *Checkers, CheckersMove, checkersUndo](g)
+}
+
 func (g Checkers) MakeMove(m game.Move) game.Game {
+	g.Apply(m.(CheckersMove))
+	return g
+}
+
+func (g *Checkers) Apply(move CheckersMove) checkersUndo {
+	u := checkersUndo{
+		move:        move,
+		piece:       g.board[move.row1][move.col1],
+		pTurn:       g.pTurn,
+		didJustJump: g.didJustJump,
+		jumpRow:     g.jumpRow,
+		jumpCol:     g.jumpCol,
+	}
 	g.round++
-	move := m.(CheckersMove)
 	g.board[move.row2][move.col2] = g.board[move.row1][move.col1]
 	g.board[move.row1][move.col1] = "."
 	if move.row1 == move.row2+2 || move.row1 == move.row2-2 {
 		rowAvg := (move.row1 + move.row2) / 2
 		colAvg := (move.col1 + move.col2) / 2
+		u.captured = g.board[rowAvg][colAvg]
 		g.board[rowAvg][colAvg] = "."
 		g.didJustJump = true
 		g.jumpRow = move.row2
@@ -95,32 +137,44 @@ func (g Checkers) MakeMove(m game.Move) game.Game {
 		g.board[move.row2][move.col2] = "O"
 	}
 	if g.didJustJump {
-		moves := g.GetPossibleMoves()
+		moves := g.PossibleMoves()
 		if len(moves) == 0 {
 			g.pTurn = !g.pTurn
 			g.didJustJump = false
-			moves := g.GetPossibleMoves()
+			moves := g.PossibleMoves()
 			if len(moves) == 0 {
 				g.pTurn = !g.pTurn
 			}
-			return g
+			return u
 		} else {
-			return g
+			return u
 		}
 	} else {
 		g.pTurn = !g.pTurn
-		moves := g.GetPossibleMoves()
+		moves := g.PossibleMoves()
 		if len(moves) == 0 {
 			g.pTurn = !g.pTurn
 		}
-		return g
+		return u
+	}
+}
+
+func (g *Checkers) Undo(u checkersUndo) {
+	g.round--
+	move := u.move
+	g.board[move.row1][move.col1] = u.piece
+	g.board[move.row2][move.col2] = "."
+	if move.row1 == move.row2+2 || move.row1 == move.row2-2 {
+		g.board[(move.row1+move.row2)/2][(move.col1+move.col2)/2] = u.captured
 	}
+	g.pTurn, g.didJustJump = u.pTurn, u.didJustJump
+	g.jumpRow, g.jumpCol = u.jumpRow, u.jumpCol
 }
 
 func (g Checkers) isGoodMove(m CheckersMove) bool {
 	possibleMoves := g.GetPossibleMoves()
 	for _, move := range possibleMoves {
-		if move == m {
+		if game.SameMove(move, m) {
 			return true
 		}
 	}
@@ -149,8 +203,8 @@ func (g Checkers) CurrentScore(p game.Player) int {
 	}
 }
 
-func (g Checkers) checkJump(i, j, rowDir, colDir int) []game.Move {
-	var moves []game.Move
+func (g Checkers) checkJump(i, j, rowDir, colDir int) []CheckersMove {
+	var moves []CheckersMove
 	peice := g.board[i][j]
 	var target1, target2 string
 	if peice == "x" || peice == "X" {
@@ -160,7 +214,7 @@ func (g Checkers) checkJump(i, j, rowDir, colDir int) []game.Move {
 		target1 = "x"
 		target2 = "X"
 	}
-	if isInside(g, i+rowDir*2, j+colDir*2) && g.board[i+rowDir*2][j+colDir*2] == "." {
+	if g.inside(i+rowDir*2, j+colDir*2) && g.board[i+rowDir*2][j+colDir*2] == "." {
 		if g.board[i+rowDir][j+colDir] == target1 || g.board[i+rowDir][j+colDir] == target2 {
 			moves = append(moves, CheckersMove{
 				row1: i,
@@ -173,9 +227,9 @@ func (g Checkers) checkJump(i, j, rowDir, colDir int) []game.Move {
 	return moves
 }
 
-func (g Checkers) checkMove(i, j, rowDir, colDir int) []game.Move {
-	var moves []game.Move
-	if isInside(g, i+rowDir, j+colDir) && g.board[i+rowDir][j+colDir] == "." {
+func (g Checkers) checkMove(i, j, rowDir, colDir int) []CheckersMove {
+	var moves []CheckersMove
+	if g.inside(i+rowDir, j+colDir) && g.board[i+rowDir][j+colDir] == "." {
 		moves = append(moves, CheckersMove{
 			row1: i,
 			col1: j,
@@ -187,8 +241,12 @@ func (g Checkers) checkMove(i, j, rowDir, colDir int) []game.Move {
 }
 
 func (g Checkers) GetPossibleMoves() []game.Move {
+	return boxMoves(g.PossibleMoves())
+}
+
+func (g Checkers) PossibleMoves() []CheckersMove {
 	if g.didJustJump {
-		var moves []game.Move
+		var moves []CheckersMove
 		peice := g.board[g.jumpRow][g.jumpCol]
 		row := g.jumpRow
 		col := g.jumpCol
@@ -202,7 +260,7 @@ func (g Checkers) GetPossibleMoves() []game.Move {
 		}
 		return moves
 	}
-	var moves []game.Move
+	var moves []CheckersMove
 	for i, row := range g.board {
 		for j, spot := range row {
 			if (g.pTurn && (spot == "X" || spot == "x")) || (!g.pTurn && spot == "O") {
@@ -260,3 +318,8 @@ func (g Checkers) BoardString() string {
 func (g Checkers) GetRound() int {
 	return g.round
 }
+
+func (g Checkers) withMaxRounds(n int) game.Game {
+	g.maxRounds = n
+	return g
+}
@@ -0,0 +1,72 @@
+package game
+
+import (
+	"github.com/damargulis/game/interfaces"
+	"time"
+)
+
+const EndTimeForfeit = "time_forfeit"
+
+// TimeControl gives each player Base to make all their moves, adding
+// Increment after each one, and PerMove for any single move. Sudden death
+// is a Base alone, Fischer a Base with an Increment. Zero turns a limit off.
+type TimeControl struct {
+	Base      time.Duration
+	Increment time.Duration
+	PerMove   time.Duration
+}
+
+func (tc TimeControl) enabled() bool {
+	return tc.Base > 0 || tc.PerMove > 0
+}
+
+// Rules are the match conditions a game is played under. Ponder lets
+// players that can search on their opponent's time do so, and Resign and
+// Draws let players concede or agree a draw. Seed seeds the random moves
+// Deadline plays, so a game replays move for move from the same seed.
+type Rules struct {
+	Adjudication Adjudication
+	Time         TimeControl
+	Deadline     Deadline
+	Ponder       bool
+	Resign       bool
+	Draws        bool
+	Seed         int64
+}
+
+type clocks struct {
+	TimeControl
+	remaining [2]time.Duration
+}
+
+func newClocks(tc TimeControl) clocks {
+	return clocks{TimeControl: tc, remaining: [2]time.Duration{tc.Base, tc.Base}}
+}
+
+func (c clocks) clock(seat int) game.Clock {
+	return game.Clock{Remaining: c.remaining[seat], Increment: c.Increment, PerMove: c.PerMove}
+}
+
+// getTurn asks p for a move, handing it its clock when there is one.
+func (c clocks) getTurn(p game.Player, g game.Game) game.Move {
+	if t, ok := p.(game.TimedPlayer); ok && c.enabled() {
+		return t.GetTimedTurn(g, c.clock(seat(p)))
+	}
+	return p.GetTurn(g)
+}
+
+// punch charges seat for a move that took elapsed, reporting false if its
+// flag fell.
+func (c *clocks) punch(seat int, elapsed time.Duration) bool {
+	if c.PerMove > 0 && elapsed > c.PerMove {
+		return false
+	}
+	if c.Base > 0 {
+		if elapsed > c.remaining[seat] {
+			c.remaining[seat] = 0
+			return false
+		}
+		c.remaining[seat] += c.Increment - elapsed
+	}
+	return true
+}
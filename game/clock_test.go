@@ -0,0 +1,113 @@
+package game
+
+import (
+	"github.com/damargulis/game/interfaces"
+	"github.com/damargulis/game/player"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestPunch(t *testing.T) {
+	ms := time.Millisecond
+	tests := []struct {
+		name    string
+		tc      TimeControl
+		elapsed []time.Duration
+		left    time.Duration
+		fallen  int
+	}{
+		{"sudden death", TimeControl{Base: 100 * ms}, []time.Duration{40 * ms, 40 * ms, 40 * ms}, 0, 2},
+		{"fischer", TimeControl{Base: 100 * ms, Increment: 30 * ms}, []time.Duration{40 * ms, 40 * ms, 40 * ms}, 70 * ms, -1},
+		{"per move", TimeControl{PerMove: 50 * ms}, []time.Duration{40 * ms, 50 * ms, 60 * ms}, 0, 2},
+		{"both", TimeControl{Base: time.Second, PerMove: 50 * ms}, []time.Duration{10 * ms, 60 * ms}, 990 * ms, 1},
+		{"exactly out", TimeControl{Base: 100 * ms}, []time.Duration{60 * ms, 40 * ms}, 0, -1},
+	}
+	for _, tt := range tests {
+		c := newClocks(tt.tc)
+		fallen := -1
+		for i, e := range tt.elapsed {
+			if !c.punch(0, e) {
+				fallen = i
+				break
+			}
+		}
+		if fallen != tt.fallen {
+			t.Errorf("%v: flag fell on move %v, want %v", tt.name, fallen, tt.fallen)
+		}
+		if c.remaining[0] != tt.left {
+			t.Errorf("%v: %v left, want %v", tt.name, c.remaining[0], tt.left)
+		}
+		if c.remaining[1] != tt.tc.Base {
+			t.Errorf("%v: the other clock ran down to %v", tt.name, c.remaining[1])
+		}
+	}
+}
+
+// clockPlayer takes delay over every move, keeping the clocks it is handed.
+type clockPlayer struct {
+	name   string
+	delay  time.Duration
+	clocks *[]game.Clock
+}
+
+func (p clockPlayer) GetName() string {
+	return p.name
+}
+
+func (p clockPlayer) GetTurn(g game.Game) game.Move {
+	time.Sleep(p.delay)
+	return g.GetPossibleMoves()[0]
+}
+
+func (p clockPlayer) GetTimedTurn(g game.Game, c game.Clock) game.Move {
+	*p.clocks = append(*p.clocks, c)
+	return p.GetTurn(g)
+}
+
+func TestFlagFallForfeitsTheGame(t *testing.T) {
+	ms := time.Millisecond
+	tests := []struct {
+		name string
+		tc   TimeControl
+		// The slow player's flag falls on this move.
+		turns int
+	}{
+		{"sudden death", TimeControl{Base: 100 * ms}, 3},
+		{"per move", TimeControl{PerMove: 20 * ms}, 1},
+	}
+	for _, tt := range tests {
+		var clocks []game.Clock
+		slow := clockPlayer{name: "Player 1", delay: 40 * ms, clocks: &clocks}
+		g := countdown{p1: slow, p2: player.ComputerPlayer{Name: "Player 2", Rand: rand.New(rand.NewSource(1))}, left: 14, p1Turn: true}
+		res := PlayWith(g, false, Rules{Time: tt.tc})
+		if res.Reason != EndTimeForfeit || res.Winner != 2 {
+			t.Errorf("%v: game ended by %q won by %v, want Player 1 to lose on time", tt.name, res.Reason, res.Winner)
+		}
+		if res.Turns[0] != tt.turns {
+			t.Errorf("%v: Player 1 made %v moves, want its flag to fall on move %v", tt.name, res.Turns[0], tt.turns)
+		}
+		for i, c := range clocks {
+			if c.PerMove != tt.tc.PerMove || c.Remaining > tt.tc.Base || i > 0 && c.Remaining >= clocks[i-1].Remaining && tt.tc.Base > 0 {
+				t.Errorf("%v: handed %+v on move %v after %+v", tt.name, c, i+1, clocks)
+			}
+		}
+	}
+}
+
+func TestIncrementsKeepTheFlagUp(t *testing.T) {
+	ms := time.Millisecond
+	var clocks []game.Clock
+	slow := clockPlayer{name: "Player 1", delay: 5 * ms, clocks: &clocks}
+	g := countdown{p1: slow, p2: player.ComputerPlayer{Name: "Player 2", Rand: rand.New(rand.NewSource(1))}, left: 14, p1Turn: true}
+	// Without the increment Player 1 would run out within its seven moves.
+	res := PlayWith(g, false, Rules{Time: TimeControl{Base: 20 * ms, Increment: 20 * ms}})
+	if res.Reason != "" {
+		t.Fatalf("game ended by %q, want it played out", res.Reason)
+	}
+	for i, c := range clocks {
+		if c.Increment != 20*ms || c.Remaining < 20*ms {
+			t.Errorf("handed %+v on move %v", c, i+1)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package game
+
+import (
+	"fmt"
+	"github.com/damargulis/game/interfaces"
+	"github.com/damargulis/game/player"
+	"math/rand"
+	"testing"
+)
+
+// countdown takes one or two off left in turn until none are left, when
+// Player 1 wins if p1Wins is set and the game is drawn otherwise, however
+// either side played.
+type countdown struct {
+	p1, p2 game.Player
+	left   int
+	p1Turn bool
+	p1Wins bool
+}
+
+func newCountdown(engine string, depth int, p1Wins bool) countdown {
+	rng := rand.New(rand.NewSource(1))
+	p1 := getPlayer(engine, "Player 1", depth, rng)
+	p2 := getPlayer(engine, "Player 2", depth, rng)
+	return countdown{p1: p1, p2: p2, left: 14, p1Turn: true, p1Wins: p1Wins}
+}
+
+func (g countdown) BoardString() string            { return fmt.Sprint(g.left) }
+func (g countdown) GetHumanInput() game.Move       { return 1 }
+func (g countdown) GetBoardDimensions() (int, int) { return 1, 1 }
+func (g countdown) GetRound() int                  { return 14 - g.left }
+func (g countdown) CurrentScore(p game.Player) int { return 0 }
+
+func (g countdown) GetPlayerTurn() game.Player {
+	if g.p1Turn {
+		return g.p1
+	}
+	return g.p2
+}
+
+func (g countdown) GetPossibleMoves() []game.Move {
+	if g.left == 1 {
+		return []game.Move{1}
+	}
+	return []game.Move{1, 2}
+}
+
+func (g countdown) MakeMove(m game.Move) game.Game {
+	g.left -= m.(int)
+	g.p1Turn = !g.p1Turn
+	return g
+}
+
+func (g countdown) GameOver() (bool, game.Player) {
+	if g.left > 0 {
+		return false, player.ComputerPlayer{}
+	} else if g.p1Wins {
+		return true, g.p1
+	}
+	return true, player.HumanPlayer{Name: "DRAW"}
+}
+
+func TestEnginesResignLostGames(t *testing.T) {
+	for _, engine := range []string{"Alphabeta", "MontecarloNodes"} {
+		t.Run(engine, func(t *testing.T) {
+			res := PlayWith(newCountdown(engine, 20000, true), false, Rules{Resign: true})
+			if res.Reason != EndResignation || res.Winner != 1 {
+				t.Errorf("game ended by %q won by %v, want Player 2 to resign", res.Reason, res.Winner)
+			}
+		})
+	}
+}
+
+func TestEnginesAgreeDrawnGames(t *testing.T) {
+	for _, engine := range []string{"Alphabeta", "AlphabetaNodes", "ComboNodes", "MontecarloNodes"} {
+		t.Run(engine, func(t *testing.T) {
+			res := PlayWith(newCountdown(engine, 20000, false), false, Rules{Draws: true})
+			// Player 1 offers after its third move and Player 2 takes it.
+			if res.Reason != EndDrawAgreed || res.Moves != 5 {
+				t.Errorf("game ended by %q after %v moves, want a draw agreed after 5", res.Reason, res.Moves)
+			}
+		})
+	}
+}
+
+func TestEnginesPlayOnWithoutConduct(t *testing.T) {
+	res := PlayWith(newCountdown("Alphabeta", 2000, true), false, Rules{})
+	if res.Reason != "" || res.Winner != 1 {
+		t.Errorf("game ended by %q won by %v, want Player 1 to win it out", res.Reason, res.Winner)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/damargulis/game/interfaces"
 	"github.com/damargulis/game/player"
+	"math/rand"
 )
 
 type Connect4 struct {
@@ -18,10 +19,19 @@ type Connect4Move struct {
 	col int
 }
 
+func (m Connect4Move) Key() uint64 {
+	return moveKey(m.col)
+}
+
 func (g Connect4) GetBoardDimensions() (int, int) {
 	return len(g.board), len(g.board[0])
 }
 
+// inside reports whether row, col is on the board.
+func (g Connect4) inside(row, col int) bool {
+	return isInside(len(g.board), len(g.board[0]), row, col)
+}
+
 func (g Connect4) BoardString() string {
 	s := "-----------------\n"
 	for i, row := range g.board {
@@ -51,7 +61,14 @@ func (g Connect4) GetHumanInput() game.Move {
 }
 
 func (g Connect4) GetPossibleMoves() []game.Move {
-	var moves []game.Move
+	return boxMoves(g.PossibleMoves())
+}
+
+func (g Connect4) PossibleMoves() []Connect4Move {
+	return g.AppendMoves(nil)
+}
+
+func (g Connect4) AppendMoves(moves []Connect4Move) []Connect4Move {
 	topRow := g.board[0]
 	for i, spot := range topRow {
 		if spot == "." {
@@ -61,12 +78,35 @@ func (g Connect4) GetPossibleMoves() []game.Move {
 	return moves
 }
 
+func (g Connect4) CheckMove(m game.Move) error {
+	move, ok := m.(Connect4Move)
+	if !ok {
+		return fmt.Errorf("%w %T, want %T", ErrWrongMoveType, m, Connect4Move{})
+	}
+	if !g.inside(0, move.col) || g.board[0][move.col] != "." {
+		return fmt.Errorf("%w %+v", ErrIllegalMove, m)
+	}
+	return nil
+}
+
+func (g Connect4) Kernel() player.Kernel {
+	return player.NewMutableKernel[Connect4, *Connect4, Connect4Move, Connect4Move](g)
+}
+
 func (g Connect4) MakeMove(m game.Move) game.Game {
+	return g.Play(m.(Connect4Move))
+}
+
+func (g Connect4) Play(move Connect4Move) Connect4 {
+	g.Apply(move)
+	return g
+}
+
+func (g *Connect4) Apply(move Connect4Move) Connect4Move {
 	g.round++
-	move := m.(Connect4Move)
 	col := move.col
 	i := 0
-	for isInside(g, i, col) && g.board[i][col] == "." {
+	for g.inside(i, col) && g.board[i][col] == "." {
 		i++
 	}
 	if g.pTurn {
@@ -75,11 +115,21 @@ func (g Connect4) MakeMove(m game.Move) game.Game {
 		g.board[i-1][col] = "O"
 	}
 	g.pTurn = !g.pTurn
-	return g
+	return move
+}
+
+func (g *Connect4) Undo(move Connect4Move) {
+	g.round--
+	i := 0
+	for g.board[i][move.col] == "." {
+		i++
+	}
+	g.board[i][move.col] = "."
+	g.pTurn = !g.pTurn
 }
 
 func (g Connect4) checkMatch(i, j, rowDir, colDir int) bool {
-	return isInside(g, i+rowDir*3, j+colDir*3) &&
+	return g.inside(i+rowDir*3, j+colDir*3) &&
 		g.board[i][j] == g.board[i+rowDir][j+colDir] &&
 		g.board[i][j] == g.board[i+rowDir*2][j+colDir*2] &&
 		g.board[i][j] == g.board[i+rowDir*3][j+colDir*3]
@@ -134,10 +184,11 @@ func (g Connect4) CurrentScore(p game.Player) int {
 	return 0
 }
 
-func NewConnect4(p1 string, p2 string, depth1 int, depth2 int) *Connect4 {
+func NewConnect4(p1 string, p2 string, depth1 int, depth2 int, seed int64) *Connect4 {
 	c := new(Connect4)
-	c.p1 = getPlayer(p1, "Player 1", depth1)
-	c.p2 = getPlayer(p2, "Player 2", depth2)
+	rng := rand.New(rand.NewSource(seed))
+	c.p1 = getPlayer(p1, "Player 1", depth1, rng)
+	c.p2 = getPlayer(p2, "Player 2", depth2, rng)
 	c.pTurn = true
 	c.board = [8][8]string{
 		{".", ".", ".", ".", ".", ".", ".", "."},
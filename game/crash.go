@@ -0,0 +1,40 @@
+package game
+
+import (
+	"github.com/damargulis/game/interfaces"
+	"github.com/damargulis/game/player"
+)
+
+const (
+	EndPlayerCrash = "player_crash"
+	EndGameCrash   = "game_crash"
+)
+
+// Crash is what Play saw of a panic that ended a game; with the game's seed
+// and opening it is enough to replay the game up to it. A panic during a
+// player's turn forfeits the game for them, one anywhere else ends it with
+// no winner.
+type Crash struct {
+	Panic string
+	Stack string
+	Board string
+	Moves []string
+}
+
+func newCrash(p player.Panic, g game.Game, moves []game.Move) *Crash {
+	c := &Crash{Panic: p.String(), Stack: p.Stack, Board: boardString(g)}
+	for _, m := range moves {
+		c.Moves = append(c.Moves, player.MoveText(m))
+	}
+	return c
+}
+
+// boardString is g's board, if printing it doesn't panic too.
+func boardString(g game.Game) (s string) {
+	defer func() {
+		if recover() != nil {
+			s = ""
+		}
+	}()
+	return g.BoardString()
+}
@@ -0,0 +1,88 @@
+package game
+
+import (
+	"github.com/damargulis/game/interfaces"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fragile is a countdown whose MakeMove panics rather than leave at or
+// fewer left.
+type fragile struct {
+	countdown
+	at int
+}
+
+func (g fragile) MakeMove(m game.Move) game.Game {
+	if g.left-m.(int) <= g.at {
+		panic("fragile board broke")
+	}
+	return fragile{g.countdown.MakeMove(m).(countdown), g.at}
+}
+
+// brittlePlayer panics on its turns from round on.
+type brittlePlayer struct {
+	name  string
+	round int
+}
+
+func (p brittlePlayer) GetName() string {
+	return p.name
+}
+
+func (p brittlePlayer) GetTurn(g game.Game) game.Move {
+	if g.GetRound() >= p.round {
+		panic("brittle player broke")
+	}
+	return 1
+}
+
+func TestPlayerPanicsForfeitTheGame(t *testing.T) {
+	for _, d := range []Deadline{{}, {Limit: time.Second}} {
+		g := countdown{p1: brittlePlayer{"Player 1", 14}, p2: brittlePlayer{"Player 2", 3}, left: 14, p1Turn: true}
+		res := PlayWith(g, false, Rules{Deadline: d})
+		if res.Reason != EndPlayerCrash || res.Winner != 1 {
+			t.Fatalf("deadline %v: game ended by %q won by %v, want Player 2 to forfeit", d.Limit, res.Reason, res.Winner)
+		}
+		c := res.Crash
+		if c.Panic != "brittle player broke" || c.Board != "11" || strings.Join(c.Moves, " ") != "1 1 1" {
+			t.Errorf("deadline %v: crash %q on board %q after %v", d.Limit, c.Panic, c.Board, c.Moves)
+		}
+		if !strings.Contains(c.Stack, "brittlePlayer.GetTurn") {
+			t.Errorf("deadline %v: the stack doesn't reach the panic:\n%v", d.Limit, c.Stack)
+		}
+	}
+}
+
+func TestGamePanicsEndWithNoWinner(t *testing.T) {
+	g := fragile{countdown{p1: brittlePlayer{"Player 1", 14}, p2: brittlePlayer{"Player 2", 14}, left: 14, p1Turn: true}, 12}
+	res := PlayWith(g, false, Rules{})
+	if res.Reason != EndGameCrash || res.Winner != 0 {
+		t.Fatalf("game ended by %q won by %v, want a game crash", res.Reason, res.Winner)
+	}
+	if c := res.Crash; c.Panic != "fragile board broke" || c.Board != "13" || strings.Join(c.Moves, " ") != "1" {
+		t.Errorf("crash %q on board %q after %v", c.Panic, c.Board, c.Moves)
+	}
+}
+
+// The engines search on goroutines of their own, which hand a panic back to
+// the turn that started them.
+func TestSearchPanicsAreRelayed(t *testing.T) {
+	for _, engine := range []string{"AlphabetaTime", "AlphabetaNodes", "MontecarloTime", "MontecarloNodes", "ComboTime", "ComboNodes"} {
+		t.Run(engine, func(t *testing.T) {
+			rng := rand.New(rand.NewSource(1))
+			p1 := getPlayer(engine, "Player 1", 50, rng)
+			p2 := getPlayer(engine, "Player 2", 50, rng)
+			g := fragile{countdown{p1: p1, p2: p2, left: 14, p1Turn: true}, 9}
+			res := PlayWith(g, false, Rules{})
+			if res.Reason != EndPlayerCrash || res.Winner != 2 {
+				t.Fatalf("game ended by %q won by %v, want Player 1 to forfeit", res.Reason, res.Winner)
+			}
+			if c := res.Crash; c.Panic != "fragile board broke" || len(c.Moves) != 0 || !strings.Contains(c.Stack, "fragile.MakeMove") {
+				t.Errorf("crash %q after %v on the stack\n%v", c.Panic, c.Moves, c.Stack)
+			}
+		})
+	}
+}
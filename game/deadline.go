@@ -0,0 +1,108 @@
+package game
+
+import (
+	"github.com/damargulis/game/interfaces"
+	"github.com/damargulis/game/player"
+	"math/rand"
+	"time"
+)
+
+const EndMoveDeadline = "move_deadline"
+
+// Deadline is a hard limit on how long Play waits for any one move, so a
+// player that hangs can't hold up an experiment. A player past the Limit
+// forfeits, or with Random has a random legal move played for it. Its
+// search can't be stopped, so it runs on in the background, taking CPU from
+// both players, and its next move waits for it first. Zero Limit waits
+// forever. Play waits for the late turn to finish before it hands the
+// player anything else, closing it included.
+type Deadline struct {
+	Limit  time.Duration
+	Random bool
+}
+
+type deadlines struct {
+	Deadline
+	late [2]chan turnResult
+	rng  *rand.Rand
+}
+
+// turnResult is a move from a turn run in the background, or the panic it
+// raised instead.
+type turnResult struct {
+	move  game.Move
+	crash *player.Panic
+}
+
+func (t turnResult) get() game.Move {
+	if t.crash != nil {
+		panic(*t.crash)
+	}
+	return t.move
+}
+
+func newDeadlines(d Deadline, seed int64) *deadlines {
+	return &deadlines{Deadline: d, rng: rand.New(rand.NewSource(seed))}
+}
+
+// getTurn runs turn for seat, reporting false if the deadline passed first.
+// A panic in turn, even one that finished late, is raised again here.
+func (d *deadlines) getTurn(seat int, turn func() game.Move) (game.Move, bool) {
+	if d.Limit <= 0 {
+		return turn(), true
+	}
+	timer := time.NewTimer(d.Limit)
+	defer timer.Stop()
+	if late := d.late[seat]; late != nil {
+		select {
+		case t := <-late:
+			d.late[seat] = nil
+			t.get()
+		case <-timer.C:
+			return nil, false
+		}
+	}
+	result := make(chan turnResult, 1)
+	go func() {
+		defer func() {
+			if v := recover(); v != nil {
+				p := player.Recovered(v)
+				result <- turnResult{crash: &p}
+			}
+		}()
+		result <- turnResult{move: turn()}
+	}()
+	select {
+	case t := <-result:
+		return t.get(), true
+	case <-timer.C:
+		d.late[seat] = result
+		return nil, false
+	}
+}
+
+// release calls done once seat's late turn, if it has one, has finished. It
+// waits on a goroutine of its own, so that a player still thinking doesn't
+// hold up the end of the game, and drops any panic, as the game is over.
+func (d *deadlines) release(seat int, done func()) {
+	late := d.late[seat]
+	if late == nil {
+		done()
+		return
+	}
+	d.late[seat] = nil
+	go func() {
+		<-late
+		done()
+	}()
+}
+
+// fallback is the move played for a player that missed the deadline in g,
+// or false if it forfeits instead.
+func (d *deadlines) fallback(g game.Game) (game.Move, bool) {
+	if !d.Random {
+		return nil, false
+	}
+	moves := g.GetPossibleMoves()
+	return moves[d.rng.Intn(len(moves))], true
+}
@@ -0,0 +1,84 @@
+package game
+
+import (
+	"github.com/damargulis/game/interfaces"
+	"github.com/damargulis/game/player"
+	"math/rand"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDeadlineFallbackFollowsTheSeed(t *testing.T) {
+	g := NewReversi("Computer", "Computer", 0, 0, 1)
+	a := newDeadlines(Deadline{Random: true}, 5)
+	b := newDeadlines(Deadline{Random: true}, 5)
+	for i := 0; i < 20; i++ {
+		ma, _ := a.fallback(g)
+		mb, ok := b.fallback(g)
+		if !ok {
+			t.Fatal("fallback reported a forfeit with Random set")
+		}
+		if ma != mb {
+			t.Fatalf("fallback %v: %v and %v from the same seed", i, ma, mb)
+		}
+	}
+}
+
+// slowPlayer takes delay over every move, counting the times it is handed
+// the history or closed while still thinking.
+type slowPlayer struct {
+	name     string
+	delay    time.Duration
+	thinking *int32
+	overlaps *int32
+	closed   chan bool
+}
+
+func newSlowPlayer(name string, delay time.Duration) slowPlayer {
+	return slowPlayer{name: name, delay: delay, thinking: new(int32), overlaps: new(int32), closed: make(chan bool, 1)}
+}
+
+func (p slowPlayer) GetName() string {
+	return p.name
+}
+
+func (p slowPlayer) GetTurn(g game.Game) game.Move {
+	atomic.StoreInt32(p.thinking, 1)
+	defer atomic.StoreInt32(p.thinking, 0)
+	time.Sleep(p.delay)
+	return g.GetPossibleMoves()[0]
+}
+
+func (p slowPlayer) check() {
+	if atomic.LoadInt32(p.thinking) == 1 {
+		atomic.AddInt32(p.overlaps, 1)
+	}
+}
+
+func (p slowPlayer) SetHistory(moves []game.Move) {
+	p.check()
+}
+
+func (p slowPlayer) Close() error {
+	p.check()
+	p.closed <- true
+	return nil
+}
+
+func TestLateTurnsFinishBeforeThePlayerIsTouched(t *testing.T) {
+	slow := newSlowPlayer("Player 1", 50*time.Millisecond)
+	g := countdown{p1: slow, p2: player.ComputerPlayer{Name: "Player 2", Rand: rand.New(rand.NewSource(1))}, left: 14, p1Turn: true}
+	res := PlayWith(g, false, Rules{Deadline: Deadline{Limit: 5 * time.Millisecond, Random: true}})
+	if res.Timeouts[0] == 0 {
+		t.Fatal("the slow player made every deadline")
+	}
+	select {
+	case <-slow.closed:
+	case <-time.After(time.Second):
+		t.Fatal("the slow player was never closed")
+	}
+	if n := atomic.LoadInt32(slow.overlaps); n != 0 {
+		t.Errorf("the slow player was handed the history or closed %v times while thinking", n)
+	}
+}
@@ -0,0 +1,65 @@
+package game
+
+import (
+	"fmt"
+	"github.com/damargulis/game/interfaces"
+	"github.com/damargulis/game/player"
+	"math/rand"
+	"reflect"
+)
+
+// remote is how a player type added at run time finds its moves: by
+// running command, or by asking the web service at url.
+type remote struct {
+	command []string
+	url     string
+}
+
+// remotes are the player types added by RegisterExternal and RegisterHTTP.
+var remotes = map[string]remote{}
+
+// RegisterExternal adds a player type that plays by running command as a
+// player.ExternalPlayer, its parameter being the seconds to give each move.
+// It must be called before any games are played.
+func RegisterExternal(name string, command []string) error {
+	if len(command) == 0 {
+		return fmt.Errorf("external engine %v has no command", name)
+	}
+	return register(name, remote{command: command})
+}
+
+// RegisterHTTP adds a player type that plays by asking the web service at
+// url as a player.HTTPPlayer, its parameter being the seconds to give each
+// move. It must be called before any games are played.
+func RegisterHTTP(name string, url string) error {
+	if url == "" {
+		return fmt.Errorf("web engine %v has no url", name)
+	}
+	return register(name, remote{url: url})
+}
+
+func register(name string, r remote) error {
+	if old, ok := remotes[name]; ok {
+		if !reflect.DeepEqual(old, r) {
+			return fmt.Errorf("player type %v is already registered differently", name)
+		}
+		return nil
+	}
+	if IsPlayerType(name) {
+		return fmt.Errorf("player type %v already exists", name)
+	}
+	remotes[name] = r
+	PlayerTypes = append(PlayerTypes, name)
+	return nil
+}
+
+func newRemote(playerType string, name string, seconds int, rng *rand.Rand) (game.Player, bool) {
+	r, ok := remotes[playerType]
+	if !ok {
+		return nil, false
+	}
+	if r.url != "" {
+		return player.NewHTTPPlayer(name, r.url, seconds, rng), true
+	}
+	return player.NewExternalPlayer(name, r.command, seconds), true
+}
@@ -0,0 +1,43 @@
+package game_test
+
+import (
+	"github.com/damargulis/game/game"
+	"github.com/damargulis/game/protocol"
+	"os"
+	"testing"
+)
+
+// engineEnv makes the test binary serve a random engine instead of running
+// the tests, so the tests can run it as an external engine.
+const engineEnv = "GAME_TEST_ENGINE"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(engineEnv) != "" {
+		s := &protocol.Server{Type: "Computer", Seed: 1}
+		if err := s.Serve(os.Stdin, os.Stdout); err != nil {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+func TestScoredGameAgainstExternalEngine(t *testing.T) {
+	os.Setenv(engineEnv, "1")
+	defer os.Unsetenv(engineEnv)
+	if err := game.RegisterExternal("TestEngine", []string{os.Args[0]}); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"reversi", "mancala"} {
+		for _, players := range [][2]string{{"TestEngine", "Computer"}, {"Computer", "TestEngine"}} {
+			g, err := game.New(name, players[0], players[1], 1, 1, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result := game.Play(g, false)
+			if result.Reason != "" {
+				t.Errorf("%v %v: game ended by %v: %v", name, players, result.Reason, result.Crash)
+			}
+		}
+	}
+}
@@ -4,31 +4,124 @@ import (
 	"fmt"
 	"github.com/damargulis/game/interfaces"
 	"github.com/damargulis/game/player"
+	"io"
+	"math/rand"
 	"os"
+	"time"
 )
 
-func getPlayer(playerType string, name string, depth int) game.Player {
+var PlayerTypes = []string{
+	"Human",
+	"Computer",
+	"Minimax",
+	"Alphabeta",
+	"AlphabetaTime",
+	"Montecarlo",
+	"MontecarloTime",
+	"ComboTime",
+	"AlphabetaNodes",
+	"MontecarloNodes",
+	"ComboNodes",
+}
+
+type Constructor func(p1 string, p2 string, depth1 int, depth2 int, seed int64) game.Game
+
+var Games = map[string]Constructor{
+	"abalone": func(p1 string, p2 string, depth1 int, depth2 int, seed int64) game.Game {
+		return NewAbalone(p1, p2, depth1, depth2, seed)
+	},
+	"bitconnect4": func(p1 string, p2 string, depth1 int, depth2 int, seed int64) game.Game {
+		return NewBitConnect4(p1, p2, depth1, depth2, seed)
+	},
+	"bitreversi": func(p1 string, p2 string, depth1 int, depth2 int, seed int64) game.Game {
+		return NewBitReversi(p1, p2, depth1, depth2, seed)
+	},
+	"bittictactoe": func(p1 string, p2 string, depth1 int, depth2 int, seed int64) game.Game {
+		return NewBitTicTacToe(p1, p2, depth1, depth2, seed)
+	},
+	"boxes": func(p1 string, p2 string, depth1 int, depth2 int, seed int64) game.Game {
+		return NewBoxes(p1, p2, depth1, depth2, seed)
+	},
+	"checkers": func(p1 string, p2 string, depth1 int, depth2 int, seed int64) game.Game {
+		return NewCheckers(p1, p2, depth1, depth2, seed)
+	},
+	"connect4": func(p1 string, p2 string, depth1 int, depth2 int, seed int64) game.Game {
+		return NewConnect4(p1, p2, depth1, depth2, seed)
+	},
+	"mancala": func(p1 string, p2 string, depth1 int, depth2 int, seed int64) game.Game {
+		return NewMancala(p1, p2, depth1, depth2, seed)
+	},
+	"martianchess": func(p1 string, p2 string, depth1 int, depth2 int, seed int64) game.Game {
+		return NewMartianChess(p1, p2, depth1, depth2, seed)
+	},
+	"ninemensmorris": func(p1 string, p2 string, depth1 int, depth2 int, seed int64) game.Game {
+		return NewNineMensMorris(p1, p2, depth1, depth2, seed)
+	},
+	"pentago": func(p1 string, p2 string, depth1 int, depth2 int, seed int64) game.Game {
+		return NewPentago(p1, p2, depth1, depth2, seed)
+	},
+	"reversi": func(p1 string, p2 string, depth1 int, depth2 int, seed int64) game.Game {
+		return NewReversi(p1, p2, depth1, depth2, seed)
+	},
+	"tictactoe": func(p1 string, p2 string, depth1 int, depth2 int, seed int64) game.Game {
+		return NewTicTacToe(p1, p2, depth1, depth2, seed)
+	},
+}
+
+func New(name string, p1 string, p2 string, depth1 int, depth2 int, seed int64) (game.Game, error) {
+	for _, t := range []string{p1, p2} {
+		if !IsPlayerType(t) {
+			return nil, fmt.Errorf("player %v not recognized", t)
+		}
+	}
+	c, ok := Games[name]
+	if !ok {
+		return nil, fmt.Errorf("game %v not recognized", name)
+	}
+	return c(p1, p2, depth1, depth2, seed), nil
+}
+
+func IsPlayerType(playerType string) bool {
+	for _, t := range PlayerTypes {
+		if t == playerType {
+			return true
+		}
+	}
+	return false
+}
+
+func getPlayer(playerType string, name string, depth int, rng *rand.Rand) game.Player {
 	var p game.Player
+	r := rand.New(rand.NewSource(rng.Int63()))
 	switch playerType {
 	case "Human":
-		p = player.HumanPlayer{name}
+		p = player.HumanPlayer{Name: name}
 	case "Computer":
-		p = player.ComputerPlayer{name}
+		p = player.ComputerPlayer{Name: name, Rand: r}
 	case "Minimax":
-		p = player.MinimaxPlayer{name, depth}
+		p = player.MinimaxPlayer{Name: name, MaxDepth: depth, Rand: r, Nodes: new(int64), Evaluation: new(player.Evaluation)}
 	case "Alphabeta":
-		p = player.AlphabetaPlayer{name, depth}
+		p = player.AlphabetaPlayer{Name: name, MaxDepth: depth, Rand: r, Nodes: new(int64), Conduct: new(player.Conduct), Evaluation: new(player.Evaluation)}
 	case "AlphabetaTime":
-		p = player.AlphabetaTimePlayer{name, depth}
+		p = player.AlphabetaTimePlayer{Name: name, MaxTime: depth, Rand: r, Nodes: new(int64), Pondering: new(player.Pondering), Evaluation: new(player.Evaluation), Conduct: new(player.Conduct)}
 	case "Montecarlo":
-		p = player.MonteCarloPlayer{name, depth}
+		p = player.MonteCarloPlayer{Name: name, MaxSims: depth, Rand: r, Nodes: new(int64), Conduct: new(player.Conduct)}
 	case "MontecarloTime":
-		p = player.MonteCarloTimePlayer{name, depth}
+		p = player.MonteCarloTimePlayer{Name: name, MaxTime: depth, Rand: r, Nodes: new(int64), Pondering: new(player.Pondering), Conduct: new(player.Conduct)}
 	case "ComboTime":
-		p = player.ComboTimePlayer{name, depth}
+		p = player.ComboTimePlayer{Name: name, MaxTime: depth, Rand: r, Nodes: new(int64), Conduct: new(player.Conduct)}
+	case "AlphabetaNodes":
+		p = player.AlphabetaTimePlayer{Name: name, MaxNodes: depth, Rand: r, Nodes: new(int64), Evaluation: new(player.Evaluation), Conduct: new(player.Conduct)}
+	case "MontecarloNodes":
+		p = player.MonteCarloTimePlayer{Name: name, MaxNodes: depth, Rand: r, Nodes: new(int64), Conduct: new(player.Conduct)}
+	case "ComboNodes":
+		p = player.ComboTimePlayer{Name: name, MaxNodes: depth, Rand: r, Nodes: new(int64), Conduct: new(player.Conduct)}
 	default:
-		fmt.Println("Player " + playerType + " not recognized")
-		os.Exit(1)
+		var ok bool
+		if p, ok = newRemote(playerType, name, depth, r); !ok {
+			fmt.Println("Player " + playerType + " not recognized")
+			os.Exit(1)
+		}
 	}
 	return p
 }
@@ -45,33 +138,217 @@ func printBoard(g game.Game, outputFile string) {
 	}
 }
 
-func Play(g game.Game, print bool) int {
+type Result struct {
+	Winner   int
+	Margin   int
+	Moves    int
+	Duration time.Duration
+	MoveTime [2]time.Duration
+	Turns    [2]int
+	Nodes    [2]int64
+	Reason   string
+	// Timeouts counts each player's moves that missed the deadline.
+	Timeouts [2]int
+	Crash    *Crash
+}
+
+func seat(p game.Player) int {
+	if p.GetName() == "Player 1" {
+		return 0
+	}
+	return 1
+}
+
+func Play(g game.Game, print bool) Result {
+	return PlayWith(g, print, Rules{})
+}
+
+// PlayWith plays g out like Play under rules, which can end it early by
+// adjudication, a fallen flag, a missed deadline, an illegal move, a
+// resignation or an agreed draw, recording why in the result's Reason. A panic in a player or
+// the game ends only this game; see Crash.
+func PlayWith(g game.Game, print bool, rules Rules) Result {
+	return PlayFrom(g, nil, print, rules)
+}
+
+// PlayFrom is PlayWith starting after the opening's moves, which players
+// that keep the game's history are handed along with the rest.
+func PlayFrom(g game.Game, opening Opening, print bool, rules Rules) (result Result) {
 	var winner game.Player
+	var players [2]game.Player
+	var history []game.Move
+	thinking := -1
+	start := time.Now()
+	deadline := newDeadlines(rules.Deadline, rules.Seed)
+	defer func() {
+		for i, p := range players {
+			if c, ok := p.(io.Closer); ok {
+				deadline.release(i, func() { c.Close() })
+			}
+		}
+	}()
+	defer func() {
+		if v := recover(); v != nil {
+			result.Crash = newCrash(player.Recovered(v), g, history)
+			if thinking >= 0 {
+				result.Winner, result.Reason = 2-thinking, EndPlayerCrash
+			} else {
+				result.Winner, result.Reason = 0, EndGameCrash
+			}
+			result.Duration = time.Since(start)
+			if print {
+				fmt.Println("Game ended by", result.Reason+":", result.Crash.Panic)
+			}
+		}
+	}()
+	judge := adjudicator{Adjudication: rules.Adjudication}
+	clock := newClocks(rules.Time)
+	g, history, err := opening.play(g)
+	if err != nil {
+		panic(err)
+	}
+	if l, ok := g.(roundLimited); ok && rules.Adjudication.MaxPlies > 0 {
+		g = l.withMaxRounds(g.GetRound() + rules.Adjudication.MaxPlies)
+	}
+	var pondering [2]game.Ponderer
+	defer func() {
+		for _, q := range pondering {
+			if q != nil {
+				q.StopPondering()
+			}
+		}
+	}()
 	over := false
 	for ; !over; over, winner = g.GameOver() {
 		if print {
 			fmt.Println(g.BoardString())
 		}
 		player := g.GetPlayerTurn()
-		g = g.MakeMove(player.GetTurn(g))
+		if players[seat(player)] == nil {
+			if n, ok := player.(game.Negotiator); ok {
+				n.Negotiate(rules.Resign, rules.Draws)
+			}
+		}
+		players[seat(player)] = player
+		moveStart := time.Now()
+		// A late turn runs on after Play moves on, so it gets copies. The
+		// history is handed over only once the player's last late turn is
+		// done.
+		pos, c, moves := g, clock, history
+		thinking = seat(player)
+		move, inTime := deadline.getTurn(seat(player), func() game.Move {
+			if h, ok := player.(game.Historian); ok {
+				h.SetHistory(moves)
+			}
+			return c.getTurn(player, pos)
+		})
+		thinking = -1
+		elapsed := time.Since(moveStart)
+		result.MoveTime[seat(player)] += elapsed
+		result.Turns[seat(player)]++
+		result.Moves++
+		if !clock.punch(seat(player), elapsed) {
+			result.Winner, result.Reason = 2-seat(player), EndTimeForfeit
+			break
+		}
+		if !inTime {
+			result.Timeouts[seat(player)]++
+			if print {
+				fmt.Println(player.GetName(), "missed the move deadline")
+			}
+			var ok bool
+			if move, ok = deadline.fallback(g); !ok {
+				result.Winner, result.Reason = 2-seat(player), EndMoveDeadline
+				break
+			}
+		}
+		offered := false
+		switch m := move.(type) {
+		case game.Resign:
+			result.Winner, result.Reason = 2-seat(player), EndResignation
+		case game.DrawOffer:
+			move, offered = m.Move, rules.Draws
+		}
+		if result.Reason != "" {
+			break
+		}
+		next, err := Apply(g, move)
+		if err != nil {
+			if print {
+				fmt.Println(player.GetName(), "played an illegal move:", err)
+			}
+			result.Winner, result.Reason = 2-seat(player), EndIllegalMove
+			break
+		}
+		history = append(history, move)
+		g = next
+		if rules.Ponder {
+			s := seat(player)
+			if q := pondering[1-s]; q != nil {
+				thinking = 1 - s
+				q.OpponentMoved(g, move)
+				pondering[1-s] = nil
+			}
+			if q, ok := player.(game.Ponderer); ok && inTime {
+				if over, _ := g.GameOver(); !over && g.GetPlayerTurn() != player {
+					thinking = s
+					q.StartPondering(g)
+					pondering[s] = q
+				}
+			}
+			thinking = -1
+		}
+		if over, _ := g.GameOver(); !over && offered {
+			if n, ok := g.GetPlayerTurn().(game.Negotiator); ok && g.GetPlayerTurn() != player && n.AcceptDraw(g) {
+				result.Winner, result.Reason = 0, EndDrawAgreed
+				break
+			}
+		}
+		if over, _ := g.GameOver(); !over {
+			// A late turn may still be searching, so its word is no good.
+			var e game.Evaluator
+			if inTime {
+				e, _ = player.(game.Evaluator)
+			}
+			if w, reason, ok := judge.check(g, seat(player), e, result.Moves); ok {
+				result.Winner, result.Reason = w, reason
+				break
+			}
+		}
+	}
+	result.Duration = time.Since(start)
+	for i, p := range players {
+		if c, ok := p.(game.NodeCounter); ok {
+			result.Nodes[i] = c.NodesSearched()
+		}
+	}
+	if players[0] != nil {
+		result.Margin = g.CurrentScore(players[0])
 	}
 	if print {
 		fmt.Println(g.BoardString())
 	}
+	if result.Reason != "" {
+		if print {
+			fmt.Println("Game ended by", result.Reason, "winner", result.Winner)
+		}
+		return result
+	}
 	name := winner.GetName()
 	if name == "DRAW" {
 		if print {
 			fmt.Println("Its a draw!")
 		}
-		return 0
+		result.Winner = 0
 	} else {
 		if print {
 			fmt.Println(name + " Wins!")
 		}
 		if name == "Player 1" {
-			return 1
+			result.Winner = 1
 		} else {
-			return 2
+			result.Winner = 2
 		}
 	}
+	return result
 }
@@ -0,0 +1,89 @@
+package game
+
+import (
+	"github.com/damargulis/game/interfaces"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// randomPositions plays games random games of the named game, returning
+// every position they pass through before the end.
+func randomPositions(name string, games int) []game.Game {
+	r := rand.New(rand.NewSource(1))
+	var positions []game.Game
+	for n := 0; n < games; n++ {
+		g := Games[name]("Computer", "Computer", 0, 0, int64(n))
+		for ply := 0; ply < 300; ply++ {
+			if over, _ := g.GameOver(); over {
+				break
+			}
+			positions = append(positions, g)
+			moves := g.GetPossibleMoves()
+			g = g.MakeMove(moves[r.Intn(len(moves))])
+		}
+	}
+	return positions
+}
+
+func gameNames() []string {
+	var names []string
+	for name := range Games {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestKeysTellEveryLegalMoveApart(t *testing.T) {
+	for _, name := range gameNames() {
+		t.Run(name, func(t *testing.T) {
+			seen := map[uint64]game.Move{}
+			for _, g := range randomPositions(name, 5) {
+				for _, m := range g.GetPossibleMoves() {
+					k, ok := m.(game.Keyer)
+					if !ok {
+						t.Fatalf("%T has no Key", m)
+					}
+					if other, ok := seen[k.Key()]; ok && !game.SameMove(other, m) {
+						t.Fatalf("%+v and %+v share key %v", other, m, k.Key())
+					}
+					seen[k.Key()] = m
+				}
+			}
+		})
+	}
+}
+
+func TestSortMovesGivesOneOrder(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, name := range gameNames() {
+		t.Run(name, func(t *testing.T) {
+			for _, g := range randomPositions(name, 2) {
+				want := append([]game.Move(nil), g.GetPossibleMoves()...)
+				game.SortMoves(want)
+				for i := 1; i < len(want); i++ {
+					if want[i-1].(game.Keyer).Key() >= want[i].(game.Keyer).Key() {
+						t.Fatalf("sorted %+v before %+v", want[i-1], want[i])
+					}
+				}
+				got := append([]game.Move(nil), want...)
+				r.Shuffle(len(got), func(i, j int) { got[i], got[j] = got[j], got[i] })
+				game.SortMoves(got)
+				if !reflect.DeepEqual(got, want) {
+					t.Fatalf("shuffled moves sorted to %+v, want %+v", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLessMoveOrdersMixedMoves(t *testing.T) {
+	moves := []game.Move{ReversiMove{row: 1}, Connect4Move{col: 3}, ReversiMove{row: 0, col: 5}, Connect4Move{col: 1}}
+	game.SortMoves(moves)
+	want := []game.Move{Connect4Move{col: 1}, Connect4Move{col: 3}, ReversiMove{row: 0, col: 5}, ReversiMove{row: 1}}
+	if !reflect.DeepEqual(moves, want) {
+		t.Errorf("SortMoves() = %+v, want %+v", moves, want)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/damargulis/game/interfaces"
 	"github.com/damargulis/game/player"
+	"math/rand"
 )
 
 type Mancala struct {
@@ -20,15 +21,29 @@ type MancalaMove struct {
 	row, col int
 }
 
+func (m MancalaMove) Key() uint64 {
+	return moveKey(m.row, m.col)
+}
+
+// mancalaUndo is the pits, stores and turn before a move, which are small
+// enough to keep whole.
+type mancalaUndo struct {
+	board     [2][6]int
+	p1Capture int
+	p2Capture int
+	pTurn     bool
+}
+
 func (g Mancala) GetBoardDimensions() (int, int) {
 	return len(g.board), len(g.board[0])
 }
 
-func NewMancala(p1 string, p2 string, depth1 int, depth2 int) *Mancala {
+func NewMancala(p1 string, p2 string, depth1 int, depth2 int, seed int64) *Mancala {
 	g := new(Mancala)
 	g.round = 0
-	g.p1 = getPlayer(p1, "Player 1", depth1)
-	g.p2 = getPlayer(p2, "Player 2", depth2)
+	rng := rand.New(rand.NewSource(seed))
+	g.p1 = getPlayer(p1, "Player 1", depth1, rng)
+	g.p2 = getPlayer(p2, "Player 2", depth2, rng)
 	g.pTurn = true
 	g.board = [2][6]int{
 		{4, 4, 4, 4, 4, 4},
@@ -92,8 +107,12 @@ func (g Mancala) GetHumanInput() game.Move {
 }
 
 func (g Mancala) GetPossibleMoves() []game.Move {
+	return boxMoves(g.PossibleMoves())
+}
+
+func (g Mancala) PossibleMoves() []MancalaMove {
 	var row int
-	var moves []game.Move
+	var moves []MancalaMove
 	if g.pTurn {
 		row = 0
 	} else {
@@ -110,9 +129,18 @@ func (g Mancala) GetPossibleMoves() []game.Move {
 	return moves
 }
 
+func (g Mancala) Kernel() player.Kernel {
+	return player.NewMutableKernel[Mancala, *Mancala, MancalaMove, mancalaUndo](g)
+}
+
 func (g Mancala) MakeMove(m game.Move) game.Game {
+	g.Apply(m.(MancalaMove))
+	return g
+}
+
+func (g *Mancala) Apply(move MancalaMove) mancalaUndo {
+	u := mancalaUndo{board: g.board, p1Capture: g.p1Capture, p2Capture: g.p2Capture, pTurn: g.pTurn}
 	g.round++
-	move := m.(MancalaMove)
 	amtInHand := g.board[move.row][move.col]
 	g.board[move.row][move.col] = 0
 	curRow := move.row
@@ -124,7 +152,7 @@ func (g Mancala) MakeMove(m game.Move) game.Game {
 					g.p1Capture++
 					amtInHand--
 					if amtInHand == 0 {
-						return g
+						return u
 					} else {
 						curRow = 1
 					}
@@ -140,7 +168,7 @@ func (g Mancala) MakeMove(m game.Move) game.Game {
 					g.p2Capture++
 					amtInHand--
 					if amtInHand == 0 {
-						return g
+						return u
 					} else {
 						curRow = 0
 					}
@@ -168,7 +196,14 @@ func (g Mancala) MakeMove(m game.Move) game.Game {
 		}
 	}
 	g.pTurn = !g.pTurn
-	return g
+	return u
+}
+
+func (g *Mancala) Undo(u mancalaUndo) {
+	g.round--
+	g.board = u.board
+	g.p1Capture, g.p2Capture = u.p1Capture, u.p2Capture
+	g.pTurn = u.pTurn
 }
 
 func (g Mancala) GameOver() (bool, game.Player) {
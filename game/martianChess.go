@@ -5,6 +5,7 @@ import (
 	"github.com/damargulis/game/interfaces"
 	"github.com/damargulis/game/player"
 	"math"
+	"math/rand"
 )
 
 type MartianChess struct {
@@ -15,20 +16,41 @@ type MartianChess struct {
 	pTurn              bool
 	lastMove           MartianChessMove
 	round              int
+	maxRounds          int
 }
 
 type MartianChessMove struct {
 	startRow, startCol, endRow, endCol int
 }
 
+func (m MartianChessMove) Key() uint64 {
+	return moveKey(m.startRow, m.startCol, m.endRow, m.endCol)
+}
+
+// martianChessUndo is a move with the piece it took and the score, turn and
+// last move before it.
+type martianChessUndo struct {
+	move               MartianChessMove
+	captured           string
+	p1points, p2points int
+	pTurn              bool
+	lastMove           MartianChessMove
+}
+
 func (g MartianChess) GetBoardDimensions() (int, int) {
 	return len(g.board), len(g.board[0])
 }
 
-func NewMartianChess(p1 string, p2 string, depth1 int, depth2 int) *MartianChess {
+// inside reports whether row, col is on the board.
+func (g MartianChess) inside(row, col int) bool {
+	return isInside(len(g.board), len(g.board[0]), row, col)
+}
+
+func NewMartianChess(p1 string, p2 string, depth1 int, depth2 int, seed int64) *MartianChess {
 	g := new(MartianChess)
-	g.p1 = getPlayer(p1, "Player 1", depth1)
-	g.p2 = getPlayer(p2, "Player 2", depth2)
+	rng := rand.New(rand.NewSource(seed))
+	g.p1 = getPlayer(p1, "Player 1", depth1, rng)
+	g.p2 = getPlayer(p2, "Player 2", depth2, rng)
 	g.pTurn = true
 	g.board = [8][4]string{
 		{"Q", "Q", "D", "."},
@@ -41,6 +63,7 @@ func NewMartianChess(p1 string, p2 string, depth1 int, depth2 int) *MartianChess
 		{".", "D", "Q", "Q"},
 	}
 	g.round = 0
+	g.maxRounds = defaultMaxRounds
 	return g
 }
 
@@ -87,7 +110,7 @@ func in(arr []int, check int) bool {
 
 func (g MartianChess) getDroneMoves(i, j, rowDir, colDir int, rows []int) []MartianChessMove {
 	var moves []MartianChessMove
-	if isInside(g, i+rowDir, j+colDir) {
+	if g.inside(i+rowDir, j+colDir) {
 		if g.board[i+rowDir][j+colDir] == "." || !in(rows, i+rowDir) {
 			moves = append(moves, MartianChessMove{
 				startRow: i,
@@ -99,7 +122,7 @@ func (g MartianChess) getDroneMoves(i, j, rowDir, colDir int, rows []int) []Mart
 		if g.board[i+rowDir][j+colDir] == "." {
 			endRow := i + rowDir*2
 			endCol := j + colDir*2
-			if isInside(g, endRow, endCol) {
+			if g.inside(endRow, endCol) {
 				if g.board[endRow][endCol] == "." || !in(rows, endRow) {
 					moves = append(moves, MartianChessMove{
 						startRow: i,
@@ -118,7 +141,7 @@ func (g MartianChess) getPawnMoves(i, j, rowDir, colDir int, rows []int) []Marti
 	var moves []MartianChessMove
 	endRow := i + rowDir
 	endCol := j + colDir
-	if isInside(g, endRow, endCol) {
+	if g.inside(endRow, endCol) {
 		if g.board[endRow][endCol] == "." || !in(rows, endRow) {
 			moves = append(moves, MartianChessMove{
 				startRow: i,
@@ -135,7 +158,7 @@ func (g MartianChess) getQueenMoves(i, j, rowDir, colDir int, rows []int) []Mart
 	var moves []MartianChessMove
 	endRow := i + rowDir
 	endCol := j + colDir
-	for isInside(g, endRow, endCol) && g.board[endRow][endCol] == "." {
+	for g.inside(endRow, endCol) && g.board[endRow][endCol] == "." {
 		moves = append(moves, MartianChessMove{
 			startRow: i,
 			startCol: j,
@@ -145,7 +168,7 @@ func (g MartianChess) getQueenMoves(i, j, rowDir, colDir int, rows []int) []Mart
 		endRow += rowDir
 		endCol += colDir
 	}
-	if isInside(g, endRow, endCol) && !in(rows, endRow) {
+	if g.inside(endRow, endCol) && !in(rows, endRow) {
 		moves = append(moves, MartianChessMove{
 			startRow: i,
 			startCol: j,
@@ -157,6 +180,10 @@ func (g MartianChess) getQueenMoves(i, j, rowDir, colDir int, rows []int) []Mart
 }
 
 func (g MartianChess) GetPossibleMoves() []game.Move {
+	return boxMoves(g.PossibleMoves())
+}
+
+func (g MartianChess) PossibleMoves() []MartianChessMove {
 	var rows []int
 	if g.pTurn {
 		rows = []int{7, 6, 5, 4}
@@ -189,7 +216,7 @@ func (g MartianChess) GetPossibleMoves() []game.Move {
 			}
 		}
 	}
-	var rMoves []game.Move
+	var rMoves []MartianChessMove
 	for _, move := range moves {
 		if move.startRow == g.lastMove.endRow && move.startCol == g.lastMove.endCol && move.endRow == g.lastMove.startRow && move.endCol == g.lastMove.startCol {
 			continue
@@ -199,9 +226,25 @@ func (g MartianChess) GetPossibleMoves() []game.Move {
 	return rMoves
 }
 
+func (g MartianChess) Kernel() player.Kernel {
+	return player.NewMutableKernel[MartianChess, *MartianChess, MartianChessMove, martianChessUndo](g)
+}
+
 func (g MartianChess) MakeMove(m game.Move) game.Game {
+	g.Apply(m.(MartianChessMove))
+	return g
+}
+
+func (g *MartianChess) Apply(move MartianChessMove) martianChessUndo {
+	u := martianChessUndo{
+		move:     move,
+		captured: g.board[move.endRow][move.endCol],
+		p1points: g.p1points,
+		p2points: g.p2points,
+		pTurn:    g.pTurn,
+		lastMove: g.lastMove,
+	}
 	g.round++
-	move := m.(MartianChessMove)
 	startRow := move.startRow
 	startCol := move.startCol
 	endRow := move.endRow
@@ -229,11 +272,21 @@ func (g MartianChess) MakeMove(m game.Move) game.Game {
 	g.board[startRow][startCol] = "."
 	g.pTurn = !g.pTurn
 	g.lastMove = move
-	return g
+	return u
+}
+
+func (g *MartianChess) Undo(u martianChessUndo) {
+	g.round--
+	move := u.move
+	g.board[move.startRow][move.startCol] = g.board[move.endRow][move.endCol]
+	g.board[move.endRow][move.endCol] = u.captured
+	g.p1points, g.p2points = u.p1points, u.p2points
+	g.pTurn = u.pTurn
+	g.lastMove = u.lastMove
 }
 
 func (g MartianChess) GameOver() (bool, game.Player) {
-	if g.round > 500 {
+	if g.round > g.maxRounds {
 		if g.p1points == g.p2points {
 			return true, player.HumanPlayer{"DRAW"}
 		} else if g.p1points > g.p2points {
@@ -310,3 +363,8 @@ func (g MartianChess) CurrentScore(p game.Player) int {
 func (g MartianChess) GetRound() int {
 	return g.round
 }
+
+func (g MartianChess) withMaxRounds(n int) game.Game {
+	g.maxRounds = n
+	return g
+}
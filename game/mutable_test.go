@@ -0,0 +1,58 @@
+package game
+
+import (
+	"github.com/damargulis/game/interfaces"
+	"math/rand"
+	"testing"
+)
+
+// checkMutable plays random games of g in place, checking each Apply leaves
+// the position MakeMove gives and each Undo, taken back from the end of the
+// game, restores the exact position before its move.
+func checkMutable[P comparable, Q interface {
+	*P
+	game.Mutable[M, U]
+}, M any, U any](t *testing.T, name string, g P, games int) {
+	r := rand.New(rand.NewSource(1))
+	for n := 0; n < games; n++ {
+		pos := g
+		var before []P
+		var undos []U
+		for {
+			if over, _ := Q(&pos).GameOver(); over {
+				break
+			}
+			moves := Q(&pos).PossibleMoves()
+			m := moves[r.Intn(len(moves))]
+			want := any(pos).(game.Game).MakeMove(any(m))
+			before = append(before, pos)
+			undos = append(undos, Q(&pos).Apply(m))
+			if any(pos) != want {
+				t.Fatalf("%v game %v ply %v: Apply(%+v) gave a different position from MakeMove", name, n, len(undos), m)
+			}
+		}
+		for i := len(undos) - 1; i >= 0; i-- {
+			Q(&pos).Undo(undos[i])
+			if pos != before[i] {
+				t.Fatalf("%v game %v ply %v: Undo didn't restore the position", name, n, i+1)
+			}
+		}
+	}
+}
+
+func TestApplyUndo(t *testing.T) {
+	games := 300
+	if testing.Short() {
+		games = 30
+	}
+	checkMutable[TicTacToe, *TicTacToe](t, "tictactoe", *NewTicTacToe("Computer", "Computer", 0, 0, 1), games)
+	checkMutable[Connect4, *Connect4](t, "connect4", *NewConnect4("Computer", "Computer", 0, 0, 1), games)
+	checkMutable[Boxes, *Boxes](t, "boxes", *NewBoxes("Computer", "Computer", 0, 0, 1), games)
+	checkMutable[Checkers, *Checkers](t, "checkers", *NewCheckers("Computer", "Computer", 0, 0, 1), games)
+	checkMutable[Reversi, *Reversi](t, "reversi", *NewReversi("Computer", "Computer", 0, 0, 1), games/10)
+	checkMutable[Abalone, *Abalone](t, "abalone", *NewAbalone("Computer", "Computer", 0, 0, 1), games/10)
+	checkMutable[Mancala, *Mancala](t, "mancala", *NewMancala("Computer", "Computer", 0, 0, 1), games)
+	checkMutable[Pentago, *Pentago](t, "pentago", *NewPentago("Computer", "Computer", 0, 0, 1), games)
+	checkMutable[MartianChess, *MartianChess](t, "martianchess", *NewMartianChess("Computer", "Computer", 0, 0, 1), games/10)
+	checkMutable[NineMensMorris, *NineMensMorris](t, "ninemensmorris", *NewNineMensMorris("Computer", "Computer", 0, 0, 1), games/10)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/damargulis/game/interfaces"
 	"github.com/damargulis/game/player"
+	"math/rand"
 )
 
 type NineMensMorris struct {
@@ -13,26 +14,47 @@ type NineMensMorris struct {
 	pTurn, stage1, justMilled bool
 	p1toPlace, p2toPlace      int
 	round                     int
+	maxRounds                 int
 }
 
 type NineMensMorrisMove struct {
 	row1, col1, row2, col2 int
 }
 
+func (m NineMensMorrisMove) Key() uint64 {
+	return moveKey(m.row1, m.col1, m.row2, m.col2)
+}
+
+// nineMensMorrisUndo is a move with what its two cells held and the state
+// of the turn before it.
+type nineMensMorrisUndo struct {
+	move                      NineMensMorrisMove
+	was1, was2                string
+	pTurn, stage1, justMilled bool
+	p1toPlace, p2toPlace      int
+}
+
 func (g NineMensMorris) GetBoardDimensions() (int, int) {
 	return len(g.board), len(g.board[0])
 }
 
-func NewNineMensMorris(p1 string, p2 string, depth1 int, depth2 int) *NineMensMorris {
+// inside reports whether row, col is on the board.
+func (g NineMensMorris) inside(row, col int) bool {
+	return isInside(len(g.board), len(g.board[0]), row, col)
+}
+
+func NewNineMensMorris(p1 string, p2 string, depth1 int, depth2 int, seed int64) *NineMensMorris {
 	g := new(NineMensMorris)
-	g.p1 = getPlayer(p1, "Player 1", depth1)
-	g.p2 = getPlayer(p2, "Player 2", depth2)
+	rng := rand.New(rand.NewSource(seed))
+	g.p1 = getPlayer(p1, "Player 1", depth1, rng)
+	g.p2 = getPlayer(p2, "Player 2", depth2, rng)
 	g.pTurn = true
 	g.stage1 = true
 	g.justMilled = false
 	g.p1toPlace = 9
 	g.p2toPlace = 9
 	g.round = 0
+	g.maxRounds = defaultMaxRounds
 	g.board = [7][7]string{
 		{".", "-", "-", ".", "-", "-", "."},
 		{"|", ".", "-", ".", "-", ".", "|"},
@@ -71,7 +93,7 @@ func (g NineMensMorris) GetPlayerTurn() game.Player {
 
 func isIn(moves []game.Move, move game.Move) bool {
 	for _, m := range moves {
-		if m == move {
+		if game.SameMove(m, move) {
 			return true
 		}
 	}
@@ -106,9 +128,13 @@ func (g NineMensMorris) GetHumanInput() game.Move {
 }
 
 func (g NineMensMorris) GetPossibleMoves() []game.Move {
+	return boxMoves(g.PossibleMoves())
+}
+
+func (g NineMensMorris) PossibleMoves() []NineMensMorrisMove {
 	if g.justMilled {
-		var moves []game.Move
-		var allMoves []game.Move
+		var moves []NineMensMorrisMove
+		var allMoves []NineMensMorrisMove
 		var target string
 		if g.pTurn {
 			target = "O"
@@ -137,7 +163,7 @@ func (g NineMensMorris) GetPossibleMoves() []game.Move {
 			return allMoves
 		}
 	} else if g.stage1 {
-		var moves []game.Move
+		var moves []NineMensMorrisMove
 		for i, row := range g.board {
 			for j, spot := range row {
 				if spot == "." {
@@ -150,7 +176,7 @@ func (g NineMensMorris) GetPossibleMoves() []game.Move {
 		}
 		return moves
 	} else {
-		var moves []game.Move
+		var moves []NineMensMorrisMove
 		var owns string
 		if g.pTurn {
 			owns = "X"
@@ -162,10 +188,10 @@ func (g NineMensMorris) GetPossibleMoves() []game.Move {
 				if spot == owns {
 					checkRow := i
 					checkCol := j + 1
-					for isInside(g, checkRow, checkCol) && g.board[checkRow][checkCol] == "-" {
+					for g.inside(checkRow, checkCol) && g.board[checkRow][checkCol] == "-" {
 						checkCol++
 					}
-					if isInside(g, checkRow, checkCol) && g.board[checkRow][checkCol] == "." {
+					if g.inside(checkRow, checkCol) && g.board[checkRow][checkCol] == "." {
 						moves = append(moves, NineMensMorrisMove{
 							row1: i,
 							col1: j,
@@ -174,10 +200,10 @@ func (g NineMensMorris) GetPossibleMoves() []game.Move {
 						})
 					}
 					checkCol = j - 1
-					for isInside(g, checkRow, checkCol) && g.board[checkRow][checkCol] == "-" {
+					for g.inside(checkRow, checkCol) && g.board[checkRow][checkCol] == "-" {
 						checkCol--
 					}
-					if isInside(g, checkRow, checkCol) && g.board[checkRow][checkCol] == "." {
+					if g.inside(checkRow, checkCol) && g.board[checkRow][checkCol] == "." {
 						moves = append(moves, NineMensMorrisMove{
 							row1: i,
 							col1: j,
@@ -187,10 +213,10 @@ func (g NineMensMorris) GetPossibleMoves() []game.Move {
 					}
 					checkCol = j
 					checkRow = i + 1
-					for isInside(g, checkRow, checkCol) && g.board[checkRow][checkCol] == "|" {
+					for g.inside(checkRow, checkCol) && g.board[checkRow][checkCol] == "|" {
 						checkRow++
 					}
-					if isInside(g, checkRow, checkCol) && g.board[checkRow][checkCol] == "." {
+					if g.inside(checkRow, checkCol) && g.board[checkRow][checkCol] == "." {
 						moves = append(moves, NineMensMorrisMove{
 							row1: i,
 							col1: j,
@@ -199,10 +225,10 @@ func (g NineMensMorris) GetPossibleMoves() []game.Move {
 						})
 					}
 					checkRow = i - 1
-					for isInside(g, checkRow, checkCol) && g.board[checkRow][checkCol] == "|" {
+					for g.inside(checkRow, checkCol) && g.board[checkRow][checkCol] == "|" {
 						checkRow--
 					}
-					if isInside(g, checkRow, checkCol) && g.board[checkRow][checkCol] == "." {
+					if g.inside(checkRow, checkCol) && g.board[checkRow][checkCol] == "." {
 						moves = append(moves, NineMensMorrisMove{
 							row1: i,
 							col1: j,
@@ -217,14 +243,32 @@ func (g NineMensMorris) GetPossibleMoves() []game.Move {
 	}
 }
 
+func (g NineMensMorris) Kernel() player.Kernel {
+	return player.NewMutableKernel[NineMensMorris, *NineMensMorris, NineMensMorrisMove, nineMensMorrisUndo](g)
+}
+
 func (g NineMensMorris) MakeMove(m game.Move) game.Game {
+	g.Apply(m.(NineMensMorrisMove))
+	return g
+}
+
+func (g *NineMensMorris) Apply(move NineMensMorrisMove) nineMensMorrisUndo {
+	u := nineMensMorrisUndo{
+		move:       move,
+		was1:       g.board[move.row1][move.col1],
+		was2:       g.board[move.row2][move.col2],
+		pTurn:      g.pTurn,
+		stage1:     g.stage1,
+		justMilled: g.justMilled,
+		p1toPlace:  g.p1toPlace,
+		p2toPlace:  g.p2toPlace,
+	}
 	g.round++
-	move := m.(NineMensMorrisMove)
 	if g.justMilled {
 		g.board[move.row1][move.col1] = "."
 		g.justMilled = false
 		g.pTurn = !g.pTurn
-		return g
+		return u
 	}
 	var toRow, toCol int
 	var own string
@@ -255,7 +299,15 @@ func (g NineMensMorris) MakeMove(m game.Move) game.Game {
 	} else {
 		g.pTurn = !g.pTurn
 	}
-	return g
+	return u
+}
+
+func (g *NineMensMorris) Undo(u nineMensMorrisUndo) {
+	g.round--
+	g.board[u.move.row2][u.move.col2] = u.was2
+	g.board[u.move.row1][u.move.col1] = u.was1
+	g.pTurn, g.stage1, g.justMilled = u.pTurn, u.stage1, u.justMilled
+	g.p1toPlace, g.p2toPlace = u.p1toPlace, u.p2toPlace
 }
 
 func (g NineMensMorris) isInMill(row, col int) bool {
@@ -264,14 +316,14 @@ func (g NineMensMorris) isInMill(row, col int) bool {
 	vertical := 0
 	checkRow := row
 	checkCol := col + 1
-	for isInside(g, checkRow, checkCol) && (g.board[checkRow][checkCol] == "-" || g.board[checkRow][checkCol] == own) {
+	for g.inside(checkRow, checkCol) && (g.board[checkRow][checkCol] == "-" || g.board[checkRow][checkCol] == own) {
 		if g.board[checkRow][checkCol] == own {
 			horizontal += 1
 		}
 		checkCol++
 	}
 	checkCol = col - 1
-	for isInside(g, checkRow, checkCol) && (g.board[checkRow][checkCol] == "-" || g.board[checkRow][checkCol] == own) {
+	for g.inside(checkRow, checkCol) && (g.board[checkRow][checkCol] == "-" || g.board[checkRow][checkCol] == own) {
 		if g.board[checkRow][checkCol] == own {
 			horizontal += 1
 		}
@@ -279,14 +331,14 @@ func (g NineMensMorris) isInMill(row, col int) bool {
 	}
 	checkCol = col
 	checkRow = row + 1
-	for isInside(g, checkRow, checkCol) && (g.board[checkRow][checkCol] == "|" || g.board[checkRow][checkCol] == own) {
+	for g.inside(checkRow, checkCol) && (g.board[checkRow][checkCol] == "|" || g.board[checkRow][checkCol] == own) {
 		if g.board[checkRow][checkCol] == own {
 			vertical += 1
 		}
 		checkRow++
 	}
 	checkRow = row - 1
-	for isInside(g, checkRow, checkCol) && (g.board[checkRow][checkCol] == "|" || g.board[checkRow][checkCol] == own) {
+	for g.inside(checkRow, checkCol) && (g.board[checkRow][checkCol] == "|" || g.board[checkRow][checkCol] == own) {
 		if g.board[checkRow][checkCol] == own {
 			vertical += 1
 		}
@@ -299,7 +351,7 @@ func (g NineMensMorris) GameOver() (bool, game.Player) {
 	if g.stage1 {
 		return false, player.ComputerPlayer{}
 	}
-	if len(g.GetPossibleMoves()) == 0 {
+	if len(g.PossibleMoves()) == 0 {
 		if g.pTurn {
 			return true, g.p2
 		} else {
@@ -317,7 +369,7 @@ func (g NineMensMorris) GameOver() (bool, game.Player) {
 			}
 		}
 	}
-	if g.round > 500 {
+	if g.round > g.maxRounds {
 		if p1 > p2 {
 			return true, g.p1
 		} else if p2 > p1 {
@@ -356,3 +408,8 @@ func (g NineMensMorris) CurrentScore(p game.Player) int {
 func (g NineMensMorris) GetRound() int {
 	return g.round
 }
+
+func (g NineMensMorris) withMaxRounds(n int) game.Game {
+	g.maxRounds = n
+	return g
+}
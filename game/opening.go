@@ -0,0 +1,118 @@
+package game
+
+import (
+	"fmt"
+	"github.com/damargulis/game/interfaces"
+	"github.com/damargulis/game/player"
+	"math/rand"
+)
+
+// Opening is a sequence of plies, each an index into GetPossibleMoves of the
+// position it is played from.
+type Opening []int
+
+func (o Opening) Apply(g game.Game) (game.Game, error) {
+	g, _, err := o.play(g)
+	return g, err
+}
+
+// play is Apply, also returning the moves it made.
+func (o Opening) play(g game.Game) (game.Game, []game.Move, error) {
+	var played []game.Move
+	for i, m := range o {
+		if over, _ := g.GameOver(); over {
+			return g, nil, fmt.Errorf("opening %v ends the game after %v plies", o, i)
+		}
+		moves := g.GetPossibleMoves()
+		if m < 0 || m >= len(moves) {
+			return g, nil, fmt.Errorf("opening %v has no move %v at ply %v", o, m, i)
+		}
+		played = append(played, moves[m])
+		g = g.MakeMove(moves[m])
+	}
+	return g, played, nil
+}
+
+// Evaluate searches depth plies ahead and scores the position for the
+// player to move, using CurrentScore at the leaves.
+func Evaluate(g game.Game, depth int) int {
+	return evaluate(g, g.GetPlayerTurn(), depth, player.MinInt, player.MaxInt)
+}
+
+func evaluate(g game.Game, p game.Player, depth int, alpha int, beta int) int {
+	if over, winner := g.GameOver(); over {
+		if winner == p {
+			return player.MaxInt
+		} else if winner.GetName() == "DRAW" {
+			return 0
+		}
+		return player.MinInt
+	}
+	if depth == 0 {
+		return g.CurrentScore(p)
+	}
+	maximize := g.GetPlayerTurn() == p
+	v := player.MaxInt
+	if maximize {
+		v = player.MinInt
+	}
+	for _, move := range g.GetPossibleMoves() {
+		score := evaluate(g.MakeMove(move), p, depth-1, alpha, beta)
+		if maximize {
+			if score > v {
+				v = score
+			}
+			if v > alpha {
+				alpha = v
+			}
+		} else {
+			if score < v {
+				v = score
+			}
+			if v < beta {
+				beta = v
+			}
+		}
+		if beta <= alpha {
+			break
+		}
+	}
+	return v
+}
+
+// RandomOpening plays plies random moves from g, retrying until the
+// position reached evaluates within maxEval of even at the given depth. If
+// no such opening turns up in a hundred tries the most even one is used.
+func RandomOpening(g game.Game, plies int, depth int, maxEval int, rng *rand.Rand) Opening {
+	var best Opening
+	bestEval := player.MaxInt
+	for try := 0; try < 100; try++ {
+		opening := make(Opening, 0, plies)
+		pos := g
+		for len(opening) < plies {
+			if over, _ := pos.GameOver(); over {
+				break
+			}
+			moves := pos.GetPossibleMoves()
+			m := rng.Intn(len(moves))
+			opening = append(opening, m)
+			pos = pos.MakeMove(moves[m])
+		}
+		if over, _ := pos.GameOver(); over {
+			continue
+		}
+		eval := Evaluate(pos, depth)
+		if eval == player.MinInt {
+			eval = player.MaxInt
+		} else if eval < 0 {
+			eval = -eval
+		}
+		if eval <= maxEval {
+			return opening
+		}
+		if best == nil || eval < bestEval {
+			best, bestEval = opening, eval
+		}
+	}
+	return best
+}
@@ -0,0 +1,71 @@
+package game
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestRandomOpeningFollowsTheSeed(t *testing.T) {
+	g := NewConnect4("Computer", "Computer", 0, 0, 1)
+	for seed := int64(0); seed < 10; seed++ {
+		a := RandomOpening(g, 4, 2, 0, rand.New(rand.NewSource(seed)))
+		b := RandomOpening(g, 4, 2, 0, rand.New(rand.NewSource(seed)))
+		if !reflect.DeepEqual(a, b) {
+			t.Errorf("seed %v: openings %v and %v", seed, a, b)
+		}
+	}
+}
+
+func TestRandomOpeningsAreLegalAndEven(t *testing.T) {
+	for _, name := range []string{"connect4", "reversi", "mancala"} {
+		g, err := New(name, "Computer", "Computer", 0, 0, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rng := rand.New(rand.NewSource(1))
+		for i := 0; i < 10; i++ {
+			o := RandomOpening(g, 4, 2, 2, rng)
+			if len(o) != 4 {
+				t.Fatalf("%v: opening %v, want 4 plies", name, o)
+			}
+			pos, err := o.Apply(g)
+			if err != nil {
+				t.Fatalf("%v: %v", name, err)
+			}
+			if over, _ := pos.GameOver(); over {
+				t.Errorf("%v: opening %v ends the game", name, o)
+			}
+			if eval := Evaluate(pos, 2); eval < -2 || eval > 2 {
+				t.Errorf("%v: opening %v is scored %v", name, o, eval)
+			}
+		}
+	}
+}
+
+// With no even opening to be had RandomOpening settles for the most even
+// one it tried.
+func TestRandomOpeningSettlesForTheMostEven(t *testing.T) {
+	g := NewTicTacToe("Computer", "Computer", 0, 0, 1)
+	o := RandomOpening(g, 5, 0, -1, rand.New(rand.NewSource(1)))
+	if o == nil {
+		t.Fatal("no opening")
+	}
+	if _, err := o.Apply(g); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestApplyRejectsBadOpenings(t *testing.T) {
+	g := NewTicTacToe("Computer", "Computer", 0, 0, 1)
+	for _, o := range []Opening{{9}, {-1}, {0, 8}, {0, 0, 0, 0, 0, 0, 0, 0, 0, 0}} {
+		if _, err := o.Apply(g); err == nil {
+			t.Errorf("opening %v applied", o)
+		}
+	}
+	for _, o := range []Opening{nil, {0, 7}, {0, 0, 0, 0}} {
+		if _, err := o.Apply(g); err != nil {
+			t.Errorf("opening %v: %v", o, err)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/damargulis/game/interfaces"
 	"github.com/damargulis/game/player"
+	"math/rand"
 	"os"
 	"strings"
 )
@@ -23,15 +24,36 @@ type PentagoMove struct {
 	clockwise      bool
 }
 
+func (m PentagoMove) Key() uint64 {
+	clockwise := 0
+	if m.clockwise {
+		clockwise = 1
+	}
+	return moveKey(m.row, m.col, m.quad, clockwise)
+}
+
+// pentagoUndo is a move with the turn and stage before it.
+type pentagoUndo struct {
+	move   PentagoMove
+	pTurn  bool
+	stage1 bool
+}
+
 func (g Pentago) GetBoardDimensions() (int, int) {
 	return len(g.board), len(g.board[0])
 }
 
-func NewPentago(p1 string, p2 string, depth1 int, depth2 int) *Pentago {
+// inside reports whether row, col is on the board.
+func (g Pentago) inside(row, col int) bool {
+	return isInside(len(g.board), len(g.board[0]), row, col)
+}
+
+func NewPentago(p1 string, p2 string, depth1 int, depth2 int, seed int64) *Pentago {
 	g := new(Pentago)
 	g.round = 0
-	g.p1 = getPlayer(p1, "Player 1", depth1)
-	g.p2 = getPlayer(p2, "Player 2", depth2)
+	rng := rand.New(rand.NewSource(seed))
+	g.p1 = getPlayer(p1, "Player 1", depth1, rng)
+	g.p2 = getPlayer(p2, "Player 2", depth2, rng)
 	g.pTurn = true
 	g.stage1 = true
 	g.board = [6][6]string{
@@ -96,7 +118,11 @@ var cRows = [4]int{1, 1, 4, 4}
 var cCols = [4]int{1, 4, 1, 4}
 
 func (g Pentago) GetPossibleMoves() []game.Move {
-	var moves []game.Move
+	return boxMoves(g.PossibleMoves())
+}
+
+func (g Pentago) PossibleMoves() []PentagoMove {
+	var moves []PentagoMove
 	if g.stage1 {
 		for i, row := range g.board {
 			for j, spot := range row {
@@ -137,9 +163,18 @@ func (g Pentago) GetPossibleMoves() []game.Move {
 	return moves
 }
 
+func (g Pentago) Kernel() player.Kernel {
+	return player.NewMutableKernel[Pentago, *Pentago, PentagoMove, pentagoUndo](g)
+}
+
 func (g Pentago) MakeMove(m game.Move) game.Game {
+	g.Apply(m.(PentagoMove))
+	return g
+}
+
+func (g *Pentago) Apply(move PentagoMove) pentagoUndo {
+	u := pentagoUndo{move: move, pTurn: g.pTurn, stage1: g.stage1}
 	g.round++
-	move := m.(PentagoMove)
 	if g.stage1 {
 		if g.pTurn {
 			g.board[move.row][move.col] = "X"
@@ -148,37 +183,51 @@ func (g Pentago) MakeMove(m game.Move) game.Game {
 		}
 		g.stage1 = false
 	} else {
-		quad := move.quad
-		dir := move.clockwise
-		cRow := cRows[quad]
-		cCol := cCols[quad]
-		if dir {
-			tmp := g.board[cRow-1][cCol-1]
-			g.board[cRow-1][cCol-1] = g.board[cRow+1][cCol-1]
-			g.board[cRow+1][cCol-1] = g.board[cRow+1][cCol+1]
-			g.board[cRow+1][cCol+1] = g.board[cRow-1][cCol+1]
-			g.board[cRow-1][cCol+1] = tmp
-			tmp = g.board[cRow-1][cCol]
-			g.board[cRow-1][cCol] = g.board[cRow][cCol-1]
-			g.board[cRow][cCol-1] = g.board[cRow+1][cCol]
-			g.board[cRow+1][cCol] = g.board[cRow][cCol+1]
-			g.board[cRow][cCol+1] = tmp
-		} else {
-			tmp := g.board[cRow-1][cCol-1]
-			g.board[cRow-1][cCol-1] = g.board[cRow-1][cCol+1]
-			g.board[cRow-1][cCol+1] = g.board[cRow+1][cCol+1]
-			g.board[cRow+1][cCol+1] = g.board[cRow+1][cCol-1]
-			g.board[cRow+1][cCol-1] = tmp
-			tmp = g.board[cRow-1][cCol]
-			g.board[cRow-1][cCol] = g.board[cRow][cCol+1]
-			g.board[cRow][cCol+1] = g.board[cRow+1][cCol]
-			g.board[cRow+1][cCol] = g.board[cRow][cCol-1]
-			g.board[cRow][cCol-1] = tmp
-		}
+		g.rotate(move.quad, move.clockwise)
 		g.pTurn = !g.pTurn
 		g.stage1 = true
 	}
-	return g
+	return u
+}
+
+func (g *Pentago) Undo(u pentagoUndo) {
+	g.round--
+	if u.stage1 {
+		g.board[u.move.row][u.move.col] = "."
+	} else {
+		g.rotate(u.move.quad, !u.move.clockwise)
+	}
+	g.pTurn = u.pTurn
+	g.stage1 = u.stage1
+}
+
+// rotate turns quadrant quad a quarter turn.
+func (g *Pentago) rotate(quad int, clockwise bool) {
+	cRow := cRows[quad]
+	cCol := cCols[quad]
+	if clockwise {
+		tmp := g.board[cRow-1][cCol-1]
+		g.board[cRow-1][cCol-1] = g.board[cRow+1][cCol-1]
+		g.board[cRow+1][cCol-1] = g.board[cRow+1][cCol+1]
+		g.board[cRow+1][cCol+1] = g.board[cRow-1][cCol+1]
+		g.board[cRow-1][cCol+1] = tmp
+		tmp = g.board[cRow-1][cCol]
+		g.board[cRow-1][cCol] = g.board[cRow][cCol-1]
+		g.board[cRow][cCol-1] = g.board[cRow+1][cCol]
+		g.board[cRow+1][cCol] = g.board[cRow][cCol+1]
+		g.board[cRow][cCol+1] = tmp
+	} else {
+		tmp := g.board[cRow-1][cCol-1]
+		g.board[cRow-1][cCol-1] = g.board[cRow-1][cCol+1]
+		g.board[cRow-1][cCol+1] = g.board[cRow+1][cCol+1]
+		g.board[cRow+1][cCol+1] = g.board[cRow+1][cCol-1]
+		g.board[cRow+1][cCol-1] = tmp
+		tmp = g.board[cRow-1][cCol]
+		g.board[cRow-1][cCol] = g.board[cRow][cCol+1]
+		g.board[cRow][cCol+1] = g.board[cRow+1][cCol]
+		g.board[cRow+1][cCol] = g.board[cRow][cCol-1]
+		g.board[cRow][cCol-1] = tmp
+	}
 }
 
 func (g Pentago) GameOver() (bool, game.Player) {
@@ -191,7 +240,7 @@ func (g Pentago) GameOver() (bool, game.Player) {
 				hasSpace = true
 				continue
 			}
-			if isInside(g, i+4, j) {
+			if g.inside(i+4, j) {
 				if spot == g.board[i+1][j] && spot == g.board[i+2][j] && spot == g.board[i+3][j] && spot == g.board[i+4][j] {
 					if spot == "X" {
 						p1win = true
@@ -200,7 +249,7 @@ func (g Pentago) GameOver() (bool, game.Player) {
 					}
 				}
 			}
-			if isInside(g, i, j+4) {
+			if g.inside(i, j+4) {
 				if spot == g.board[i][j+1] && spot == g.board[i][j+2] && spot == g.board[i][j+3] && spot == g.board[i][j+4] {
 					if spot == "X" {
 						p1win = true
@@ -209,7 +258,7 @@ func (g Pentago) GameOver() (bool, game.Player) {
 					}
 				}
 			}
-			if isInside(g, i+4, j+4) {
+			if g.inside(i+4, j+4) {
 				if spot == g.board[i+1][j+1] && spot == g.board[i+2][j+2] && spot == g.board[i+3][j+3] && spot == g.board[i+4][j+4] {
 					if spot == "X" {
 						p1win = true
@@ -218,7 +267,7 @@ func (g Pentago) GameOver() (bool, game.Player) {
 					}
 				}
 			}
-			if isInside(g, i-4, j+4) {
+			if g.inside(i-4, j+4) {
 				if spot == g.board[i-1][j+1] && spot == g.board[i-2][j+2] && spot == g.board[i-3][j+3] && spot == g.board[i-4][j+4] {
 					if spot == "X" {
 						p1win = true
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/damargulis/game/interfaces"
 	"github.com/damargulis/game/player"
+	"math/rand"
 )
 
 type Reversi struct {
@@ -18,10 +19,27 @@ type ReversiMove struct {
 	row, col int
 }
 
+func (m ReversiMove) Key() uint64 {
+	return moveKey(m.row, m.col)
+}
+
+// reversiUndo is a move with the discs it flipped, one bit for each cell
+// by row and column, and whose turn it was.
+type reversiUndo struct {
+	move    ReversiMove
+	flipped uint64
+	pTurn   bool
+}
+
 func (g Reversi) GetBoardDimensions() (int, int) {
 	return len(g.board), len(g.board[0])
 }
 
+// inside reports whether row, col is on the board.
+func (g Reversi) inside(row, col int) bool {
+	return isInside(len(g.board), len(g.board[0]), row, col)
+}
+
 func (g Reversi) BoardString() string {
 	s := "-----------------\n"
 	s += "  0 1 2 3 4 5 6 7\n"
@@ -51,21 +69,21 @@ func (g Reversi) GetHumanInput() game.Move {
 	return ReversiMove{row: spot[0], col: spot[1]}
 }
 
-func (g Reversi) checkMove(i, j, rowDir, colDir int) bool {
+func (g *Reversi) checkMove(i, j, rowDir, colDir int) bool {
 	var target, match string
 	if g.pTurn {
 		target, match = "O", "X"
 	} else {
 		target, match = "X", "O"
 	}
-	if isInside(g, i+rowDir, j+colDir) && g.board[i+rowDir][j+colDir] == target {
+	if g.inside(i+rowDir, j+colDir) && g.board[i+rowDir][j+colDir] == target {
 		rowCheck := i + rowDir
 		colCheck := j + colDir
-		for isInside(g, rowCheck, colCheck) && g.board[rowCheck][colCheck] == target {
+		for g.inside(rowCheck, colCheck) && g.board[rowCheck][colCheck] == target {
 			rowCheck += rowDir
 			colCheck += colDir
 		}
-		if isInside(g, rowCheck, colCheck) && g.board[rowCheck][colCheck] == match {
+		if g.inside(rowCheck, colCheck) && g.board[rowCheck][colCheck] == match {
 			return true
 		}
 	}
@@ -73,56 +91,94 @@ func (g Reversi) checkMove(i, j, rowDir, colDir int) bool {
 }
 
 func (g Reversi) GetPossibleMoves() []game.Move {
-	var moves []game.Move
+	return boxMoves(g.PossibleMoves())
+}
+
+func (g Reversi) PossibleMoves() []ReversiMove {
+	return g.AppendMoves(nil)
+}
+
+func (g Reversi) AppendMoves(moves []ReversiMove) []ReversiMove {
 	for i, row := range g.board {
-		for j, spot := range row {
-			if spot == "." {
-				if g.checkMove(i, j, 0, 1) ||
-					g.checkMove(i, j, 1, 1) ||
-					g.checkMove(i, j, 1, 0) ||
-					g.checkMove(i, j, 1, 0) ||
-					g.checkMove(i, j, 1, -1) ||
-					g.checkMove(i, j, 0, -1) ||
-					g.checkMove(i, j, -1, -1) ||
-					g.checkMove(i, j, -1, 0) ||
-					g.checkMove(i, j, -1, 1) {
-					moves = append(moves, ReversiMove{
-						row: i,
-						col: j,
-					})
-				}
+		for j := range row {
+			if g.canPlay(i, j) {
+				moves = append(moves, ReversiMove{
+					row: i,
+					col: j,
+				})
 			}
 		}
 	}
 	return moves
 }
 
-func (g Reversi) checkAndFill(i, j, rowDir, colDir int) Reversi {
+// canPlay reports whether the player to move may place a disc at i, j.
+func (g *Reversi) canPlay(i, j int) bool {
+	return g.board[i][j] == "." &&
+		(g.checkMove(i, j, 0, 1) ||
+			g.checkMove(i, j, 1, 1) ||
+			g.checkMove(i, j, 1, 0) ||
+			g.checkMove(i, j, 1, -1) ||
+			g.checkMove(i, j, 0, -1) ||
+			g.checkMove(i, j, -1, -1) ||
+			g.checkMove(i, j, -1, 0) ||
+			g.checkMove(i, j, -1, 1))
+}
+
+// canMove reports whether the player to move has any move, without listing
+// them.
+func (g *Reversi) canMove() bool {
+	for i, row := range g.board {
+		for j := range row {
+			if g.canPlay(i, j) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (g *Reversi) checkAndFill(i, j, rowDir, colDir int) uint64 {
+	var flipped uint64
 	var target, match string
 	if g.pTurn {
 		target, match = "O", "X"
 	} else {
 		target, match = "X", "O"
 	}
-	if isInside(g, i+rowDir, j+colDir) && g.board[i+rowDir][j+colDir] == target {
+	if g.inside(i+rowDir, j+colDir) && g.board[i+rowDir][j+colDir] == target {
 		rowCheck := i + rowDir
 		colCheck := j + colDir
-		for isInside(g, rowCheck, colCheck) && g.board[rowCheck][colCheck] == target {
+		for g.inside(rowCheck, colCheck) && g.board[rowCheck][colCheck] == target {
 			rowCheck += rowDir
 			colCheck += colDir
 		}
-		if isInside(g, rowCheck, colCheck) && g.board[rowCheck][colCheck] == match {
-			for r, c := i, j; r != rowCheck || c != colCheck; r, c = r+rowDir, c+colDir {
+		if g.inside(rowCheck, colCheck) && g.board[rowCheck][colCheck] == match {
+			for r, c := i+rowDir, j+colDir; r != rowCheck || c != colCheck; r, c = r+rowDir, c+colDir {
 				g.board[r][c] = match
+				flipped |= 1 << uint(r*len(g.board[0])+c)
 			}
 		}
 	}
-	return g
+	return flipped
+}
+
+func (g Reversi) Kernel() player.Kernel {
+	return player.NewMutableKernel[Reversi, *Reversi, ReversiMove, reversiUndo](g)
 }
 
 func (g Reversi) MakeMove(m game.Move) game.Game {
+	return g.Play(m.(ReversiMove))
+}
+
+func (g Reversi) Play(move ReversiMove) Reversi {
+	g.Apply(move)
+	return g
+}
+
+func (g *Reversi) Apply(move ReversiMove) reversiUndo {
+	u := reversiUndo{move: move, pTurn: g.pTurn}
 	g.round++
-	move := m.(ReversiMove)
 	var match string
 	if g.pTurn {
 		match = "X"
@@ -130,25 +186,40 @@ func (g Reversi) MakeMove(m game.Move) game.Game {
 		match = "O"
 	}
 	g.board[move.row][move.col] = match
-	g = g.checkAndFill(move.row, move.col, 0, 1)
-	g = g.checkAndFill(move.row, move.col, 1, 1)
-	g = g.checkAndFill(move.row, move.col, 1, 0)
-	g = g.checkAndFill(move.row, move.col, 1, -1)
-	g = g.checkAndFill(move.row, move.col, 0, -1)
-	g = g.checkAndFill(move.row, move.col, -1, -1)
-	g = g.checkAndFill(move.row, move.col, -1, 0)
-	g = g.checkAndFill(move.row, move.col, -1, 1)
+	u.flipped |= g.checkAndFill(move.row, move.col, 0, 1)
+	u.flipped |= g.checkAndFill(move.row, move.col, 1, 1)
+	u.flipped |= g.checkAndFill(move.row, move.col, 1, 0)
+	u.flipped |= g.checkAndFill(move.row, move.col, 1, -1)
+	u.flipped |= g.checkAndFill(move.row, move.col, 0, -1)
+	u.flipped |= g.checkAndFill(move.row, move.col, -1, -1)
+	u.flipped |= g.checkAndFill(move.row, move.col, -1, 0)
+	u.flipped |= g.checkAndFill(move.row, move.col, -1, 1)
 	g.pTurn = !g.pTurn
-	possibleMoves := g.GetPossibleMoves()
-	if len(possibleMoves) == 0 {
+	if !g.canMove() {
 		g.pTurn = !g.pTurn
 	}
-	return g
+	return u
+}
+
+func (g *Reversi) Undo(u reversiUndo) {
+	g.round--
+	g.pTurn = u.pTurn
+	g.board[u.move.row][u.move.col] = "."
+	target := "X"
+	if g.pTurn {
+		target = "O"
+	}
+	for i, row := range g.board {
+		for j := range row {
+			if u.flipped&(1<<uint(i*len(row)+j)) != 0 {
+				g.board[i][j] = target
+			}
+		}
+	}
 }
 
 func (g Reversi) GameOver() (bool, game.Player) {
-	possibleMoves := g.GetPossibleMoves()
-	if len(possibleMoves) == 0 {
+	if !g.canMove() {
 		score := g.CurrentScore(g.p1)
 		if score > 0 {
 			return true, g.p1
@@ -180,11 +251,12 @@ func (g Reversi) CurrentScore(p game.Player) int {
 	}
 }
 
-func NewReversi(p1 string, p2 string, depth1 int, depth2 int) *Reversi {
+func NewReversi(p1 string, p2 string, depth1 int, depth2 int, seed int64) *Reversi {
 	r := new(Reversi)
 	r.round = 0
-	r.p1 = getPlayer(p1, "Player 1", depth1)
-	r.p2 = getPlayer(p2, "Player 2", depth2)
+	rng := rand.New(rand.NewSource(seed))
+	r.p1 = getPlayer(p1, "Player 1", depth1, rng)
+	r.p2 = getPlayer(p2, "Player 2", depth2, rng)
 	r.pTurn = true
 	r.board = [8][8]string{
 		{".", ".", ".", ".", ".", ".", ".", "."},
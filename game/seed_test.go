@@ -0,0 +1,64 @@
+package game
+
+import (
+	"github.com/damargulis/game/player"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// replay plays up to plies moves of name from seed, returning them as
+// player.MoveText writes them.
+func replay(t *testing.T, name string, seed int64, plies int) []string {
+	t.Helper()
+	g, err := New(name, "Computer", "Alphabeta", 0, 1, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var moves []string
+	for len(moves) < plies {
+		if over, _ := g.GameOver(); over {
+			break
+		}
+		m := g.GetPlayerTurn().GetTurn(g)
+		moves = append(moves, player.MoveText(m))
+		g = g.MakeMove(m)
+	}
+	return moves
+}
+
+func TestGamesReplayFromTheirSeed(t *testing.T) {
+	for _, name := range gameNames() {
+		t.Run(name, func(t *testing.T) {
+			first := replay(t, name, 3, 40)
+			if again := replay(t, name, 3, 40); !reflect.DeepEqual(again, first) {
+				t.Errorf("seed 3 played\n%v\nthen\n%v", first, again)
+			}
+			for seed := int64(4); seed < 8; seed++ {
+				if !reflect.DeepEqual(replay(t, name, seed, 40), first) {
+					return
+				}
+			}
+			t.Errorf("seeds 3 to 7 all played %v", first)
+		})
+	}
+}
+
+// A game played out from the same seed ends the same way, down to the nodes
+// each engine searched.
+func TestPlayReplaysFromTheSeed(t *testing.T) {
+	play := func() Result {
+		g, err := New("connect4", "Montecarlo", "Computer", 20, 0, 9)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return PlayWith(g, false, Rules{Seed: 9})
+	}
+	first, again := play(), play()
+	for _, r := range []*Result{&first, &again} {
+		r.Duration, r.MoveTime = 0, [2]time.Duration{}
+	}
+	if !reflect.DeepEqual(first, again) {
+		t.Errorf("seed 9 played %+v then %+v", first, again)
+	}
+}
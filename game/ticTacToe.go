@@ -1,8 +1,10 @@
 package game
 
 import (
+	"fmt"
 	"github.com/damargulis/game/interfaces"
 	"github.com/damargulis/game/player"
+	"math/rand"
 )
 
 type TicTacToe struct {
@@ -17,10 +19,19 @@ type TicTacToeMove struct {
 	row, col int
 }
 
+func (m TicTacToeMove) Key() uint64 {
+	return moveKey(m.row, m.col)
+}
+
 func (g TicTacToe) GetBoardDimensions() (int, int) {
 	return len(g.board), len(g.board[0])
 }
 
+// inside reports whether row, col is on the board.
+func (g TicTacToe) inside(row, col int) bool {
+	return isInside(len(g.board), len(g.board[0]), row, col)
+}
+
 func (g TicTacToe) GetHumanInput() game.Move {
 	spot := readInts("Spot to place: ")
 	return TicTacToeMove{row: spot[0], col: spot[1]}
@@ -93,11 +104,12 @@ func (g TicTacToe) GameOver() (bool, game.Player) {
 	return true, player.HumanPlayer{"DRAW"}
 }
 
-func NewTicTacToe(p1 string, p2 string, depth1 int, depth2 int) *TicTacToe {
+func NewTicTacToe(p1 string, p2 string, depth1 int, depth2 int, seed int64) *TicTacToe {
 	g := new(TicTacToe)
 	g.round = 0
-	g.p1 = getPlayer(p1, "Player 1", depth1)
-	g.p2 = getPlayer(p2, "Player 2", depth2)
+	rng := rand.New(rand.NewSource(seed))
+	g.p1 = getPlayer(p1, "Player 1", depth1, rng)
+	g.p2 = getPlayer(p2, "Player 2", depth2, rng)
 	g.pTurn = true
 	g.board = [3][3]string{
 		{".", ".", "."},
@@ -107,9 +119,21 @@ func NewTicTacToe(p1 string, p2 string, depth1 int, depth2 int) *TicTacToe {
 	return g
 }
 
+func (g TicTacToe) Kernel() player.Kernel {
+	return player.NewMutableKernel[TicTacToe, *TicTacToe, TicTacToeMove, TicTacToeMove](g)
+}
+
 func (g TicTacToe) MakeMove(m game.Move) game.Game {
+	return g.Play(m.(TicTacToeMove))
+}
+
+func (g TicTacToe) Play(move TicTacToeMove) TicTacToe {
+	g.Apply(move)
+	return g
+}
+
+func (g *TicTacToe) Apply(move TicTacToeMove) TicTacToeMove {
 	g.round++
-	move := m.(TicTacToeMove)
 	row := move.row
 	col := move.col
 	if g.pTurn {
@@ -118,17 +142,41 @@ func (g TicTacToe) MakeMove(m game.Move) game.Game {
 		g.board[row][col] = "O"
 	}
 	g.pTurn = !g.pTurn
-	return g
+	return move
+}
+
+func (g *TicTacToe) Undo(move TicTacToeMove) {
+	g.round--
+	g.board[move.row][move.col] = "."
+	g.pTurn = !g.pTurn
+}
+
+func (g TicTacToe) CheckMove(m game.Move) error {
+	move, ok := m.(TicTacToeMove)
+	if !ok {
+		return fmt.Errorf("%w %T, want %T", ErrWrongMoveType, m, TicTacToeMove{})
+	}
+	if !g.isGoodMove(move) {
+		return fmt.Errorf("%w %+v", ErrIllegalMove, m)
+	}
+	return nil
 }
 
 func (g TicTacToe) isGoodMove(m TicTacToeMove) bool {
 	row := m.row
 	col := m.col
-	return isInside(g, row, col) && g.board[row][col] == "."
+	return g.inside(row, col) && g.board[row][col] == "."
 }
 
 func (g TicTacToe) GetPossibleMoves() []game.Move {
-	var moves []game.Move
+	return boxMoves(g.PossibleMoves())
+}
+
+func (g TicTacToe) PossibleMoves() []TicTacToeMove {
+	return g.AppendMoves(nil)
+}
+
+func (g TicTacToe) AppendMoves(moves []TicTacToeMove) []TicTacToeMove {
 	for i, row := range g.board {
 		for j, spot := range row {
 			if spot == "." {
@@ -22,7 +22,29 @@ func readInts(prompt string) []int {
 	return nums
 }
 
-func isInside(g game.Game, row, col int) bool {
-	maxRow, maxCol := g.GetBoardDimensions()
-	return row >= 0 && row < maxRow && col >= 0 && col < maxCol
+// boxMoves is moves as Moves, for a Typed game's GetPossibleMoves.
+func boxMoves[M game.Move](moves []M) []game.Move {
+	boxed := make([]game.Move, len(moves))
+	for i, m := range moves {
+		boxed[i] = m
+	}
+	return boxed
+}
+
+// moveKey packs a move's fields into a key that orders moves by each field
+// in turn. Fields are kept to their low 8 bits, which is all of any field
+// from -128 to 127.
+func moveKey(fields ...int) uint64 {
+	var k uint64
+	for _, f := range fields {
+		k = k<<8 | uint64(uint8(f+128))
+	}
+	return k
+}
+
+// isInside reports whether row, col is on a board of rows by cols. It
+// takes the dimensions rather than the game so that checking a cell doesn't
+// copy the game into an interface.
+func isInside(rows, cols, row, col int) bool {
+	return row >= 0 && row < rows && col >= 0 && col < cols
 }
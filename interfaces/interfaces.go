@@ -1,5 +1,13 @@
 package game
 
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+	"time"
+)
+
 type Player interface {
 	GetTurn(Game) Move
 	GetName() string
@@ -19,3 +27,150 @@ type Game interface {
 	GetBoardDimensions() (int, int)
 	GetRound() int
 }
+
+// Position is a game played with moves of type M that leads to positions of
+// type P, so a search over it needn't box each move into a Move or each
+// position into a Game.
+type Position[P any, M any] interface {
+	GetPlayerTurn() Player
+	PossibleMoves() []M
+	Play(M) P
+	GameOver() (bool, Player)
+	CurrentScore(Player) int
+}
+
+// Boxed is any Game as a Position over Moves.
+type Boxed struct {
+	Game
+}
+
+func (b Boxed) PossibleMoves() []Move {
+	return b.GetPossibleMoves()
+}
+
+func (b Boxed) Play(m Move) Boxed {
+	return Boxed{b.MakeMove(m)}
+}
+
+// Mutable is a position that plays and takes back moves in place, so a
+// search can walk the tree on one board rather than copying it at every
+// node. Apply returns what Undo needs to take the move back.
+type Mutable[M any, U any] interface {
+	GetPlayerTurn() Player
+	PossibleMoves() []M
+	Apply(M) U
+	Undo(U)
+	GameOver() (bool, Player)
+	CurrentScore(Player) int
+}
+
+// MoveAppender is a position that lists its moves onto the end of a slice,
+// so a search can list every position's moves into the same one.
+type MoveAppender[M any] interface {
+	AppendMoves([]M) []M
+}
+
+// RandomMover is a position that picks a random legal move without listing
+// them all. It draws from r just as moves[r.Intn(len(moves))] would, so a
+// playout goes the same way either way.
+type RandomMover[M any] interface {
+	RandomMove(*rand.Rand) M
+}
+
+type NodeCounter interface {
+	NodesSearched() int64
+}
+
+// Clock is what a player has left to think with under a time control.
+// Remaining is zero when only PerMove limits the move.
+type Clock struct {
+	Remaining time.Duration
+	Increment time.Duration
+	PerMove   time.Duration
+}
+
+type TimedPlayer interface {
+	GetTimedTurn(Game, Clock) Move
+}
+
+// Ponderer searches on the opponent's time. Play calls StartPondering with
+// the position the opponent is to move in, OpponentMoved with the position
+// after their reply, and StopPondering when the game ends.
+type Ponderer interface {
+	StartPondering(Game)
+	OpponentMoved(Game, Move)
+	StopPondering()
+}
+
+// Resign can be returned from a turn in place of a move to concede.
+type Resign struct{}
+
+// DrawOffer plays Move and offers the opponent a draw in the position it
+// leads to.
+type DrawOffer struct {
+	Move Move
+}
+
+// Negotiator can end a game by agreement. Play calls Negotiate before the
+// player's first move with whether the game allows resigning and offering
+// draws, and AcceptDraw when the opponent offers one.
+type Negotiator interface {
+	Negotiate(resign, draws bool)
+	AcceptDraw(Game) bool
+}
+
+// Historian is handed every move played so far, from the game's start and
+// openings included, before each of its turns.
+type Historian interface {
+	SetHistory([]Move)
+}
+
+// Evaluator reports how its own search scored the move it just played, for
+// itself and on CurrentScore's scale, so a referee can adjudicate the game
+// on the engines' word. ok is false when it moved without searching.
+type Evaluator interface {
+	LastEval() (score int, ok bool)
+}
+
+// MoveChecker is a game that can check a move is legal more cheaply than by
+// listing every legal move. CheckMove is only asked while the game is on.
+type MoveChecker interface {
+	CheckMove(Move) error
+}
+
+// Keyer is a move with a key for maps and sorting: the same move always has
+// the same key, and keys order a game's moves the same way on every run.
+// Two different moves on the board have different keys, but a move that
+// couldn't be on the board may share one, so SameMove is what tells moves
+// apart. Every game's moves are Keyers.
+type Keyer interface {
+	Key() uint64
+}
+
+// SameMove reports whether a and b are the same move: the same type with
+// the same fields.
+func SameMove(a, b Move) bool {
+	return a == b
+}
+
+// LessMove orders moves by key, and moves of different types, or without
+// keys, by how they print.
+func LessMove(a, b Move) bool {
+	ta, tb := reflect.TypeOf(a), reflect.TypeOf(b)
+	if ta != tb {
+		return fmt.Sprint(ta) < fmt.Sprint(tb)
+	}
+	ka, okA := a.(Keyer)
+	kb, okB := b.(Keyer)
+	if okA && okB {
+		return ka.Key() < kb.Key()
+	}
+	return fmt.Sprint(a) < fmt.Sprint(b)
+}
+
+// SortMoves puts moves in LessMove order.
+func SortMoves(moves []Move) {
+	sort.SliceStable(moves, func(i, j int) bool {
+		return LessMove(moves[i], moves[j])
+	})
+}
@@ -1,130 +1,288 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"github.com/damargulis/game/experiment"
 	"github.com/damargulis/game/game"
-	//	interfaces "github.com/damargulis/game/interfaces"
-	"math/rand"
+	"github.com/damargulis/game/protocol"
+	"github.com/damargulis/game/report"
+	"net"
 	"os"
+	"strings"
 	"time"
 )
 
-func writeOutput(fileName string, p1depth, p2depth, p1wins, p2wins, ties int, time time.Duration) {
-	f, err := os.OpenFile(fileName, os.O_APPEND|os.O_WRONLY, 0600)
-	defer f.Close()
+var defaultGames = []string{
+	"tictactoe",
+	"pentago",
+	"mancala",
+	"boxes",
+	"connect4",
+	"reversi",
+	"checkers",
+	"martianchess",
+	"ninemensmorris",
+	//	"abalone",
+}
+
+func runReport(args []string) {
+	flags := flag.NewFlagSet("report", flag.ExitOnError)
+	format := flags.String("format", "html", "report format, html or md")
+	output := flags.String("o", "", "file to write the report to (default stdout)")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: game report [-format html|md] [-o file] results.csv|results.jsonl ...")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	records, err := report.Load(flags.Args())
 	if err != nil {
-		panic(err)
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	out := os.Stdout
+	if *output != "" {
+		if out, err = os.Create(*output); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		defer out.Close()
 	}
-	if _, err = f.WriteString(fmt.Sprintf("%v,%v,%v,%v,%v,%v\n", p1depth, p2depth, p1wins, p2wins, ties, time)); err != nil {
-		panic(err)
+	if err := report.Write(out, records, *format); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
 	}
 }
 
-func runExperiment(wrapper func(string, string, int, int) int, p1 string, p2 string, outputFile string) {
-	fmt.Println("Running Experiment", outputFile, "with players", p1, p2)
-	outputFile = outputFile + ".csv"
-	for curMax := 0; curMax < 40; curMax += 2 {
-		for curMin := 0; curMin < curMax; curMin += 2 {
-			wins := [3]int{0, 0, 0}
-			start := time.Now()
-			for i := 0; i < 100; i++ {
-				win := wrapper(p1, p2, curMax, curMin)
-				wins[win]++
-			}
-			t := time.Now()
-			elapsed := t.Sub(start)
-			writeOutput(outputFile, curMax, curMin, wins[1], wins[2], wins[0], elapsed)
-			wins = [3]int{0, 0, 0}
-			start = time.Now()
-			for i := 0; i < 100; i++ {
-				win := wrapper(p1, p2, curMin, curMax)
-				wins[win]++
-			}
-			t = time.Now()
-			elapsed = t.Sub(start)
-			writeOutput(outputFile, curMin, curMax, wins[1], wins[2], wins[0], elapsed)
+// namedFlag collects repeated name=value flags.
+type namedFlag map[string]string
+
+func (n namedFlag) String() string {
+	return ""
+}
+
+func (n namedFlag) Set(s string) error {
+	name, value, _ := strings.Cut(s, "=")
+	if name == "" || strings.TrimSpace(value) == "" {
+		return fmt.Errorf("want name=value, got %q", s)
+	}
+	n[name] = value
+	return nil
+}
+
+func (n namedFlag) commands() map[string][]string {
+	commands := map[string][]string{}
+	for name, command := range n {
+		commands[name] = strings.Fields(command)
+	}
+	return commands
+}
+
+func runWorker(args []string) {
+	flags := flag.NewFlagSet("worker", flag.ExitOnError)
+	connect := flags.String("connect", "localhost:7070", "address of the coordinator")
+	parallel := flags.Int("j", 1, "games to play at once")
+	patience := flags.Duration("patience", time.Minute, "how long to keep retrying an unreachable coordinator")
+	host, _ := os.Hostname()
+	name := flags.String("name", fmt.Sprintf("%v-%v", host, os.Getpid()), "name to report to the coordinator")
+	external := namedFlag{}
+	flags.Var(external, "external", "run command as the engine type name, as name=command; the coordinator's external engines must be given here too")
+	web := namedFlag{}
+	flags.Var(web, "http", "ask the web service at url as the engine type name, as name=url; the coordinator's web engines must be given here too")
+	flags.Parse(args)
+	for name, command := range external.commands() {
+		if err := game.RegisterExternal(name, command); err != nil {
+			fmt.Println(err)
+			os.Exit(2)
 		}
-		wins := [3]int{0, 0, 0}
-		start := time.Now()
-		for i := 0; i < 100; i++ {
-			win := wrapper(p1, p2, curMax, curMax)
-			wins[win]++
+	}
+	for name, url := range web {
+		if err := game.RegisterHTTP(name, url); err != nil {
+			fmt.Println(err)
+			os.Exit(2)
 		}
-		t := time.Now()
-		elapsed := t.Sub(start)
-		writeOutput(outputFile, curMax, curMax, wins[1], wins[2], wins[0], elapsed)
-		fmt.Println("Finished depth", curMax)
+	}
+	if err := experiment.RunWorker(*connect, *name, *parallel, *patience); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
+func runEngine(args []string) {
+	flags := flag.NewFlagSet("engine", flag.ExitOnError)
+	engine := flags.String("engine", "AlphabetaTime:1", "engine to play as, as Type:param")
+	seed := flags.Int64("seed", 1, "seed for the engine's random choices")
+	flags.Parse(args)
+	axes, err := experiment.ParseAxes(*engine)
+	if err != nil || len(axes) != 1 || axes[0].From != axes[0].To || !game.IsPlayerType(axes[0].Type) || axes[0].Type == "Human" {
+		fmt.Fprintf(os.Stderr, "engine %q should be a single Type:param\n", *engine)
+		os.Exit(2)
+	}
+	s := &protocol.Server{Type: axes[0].Type, Param: axes[0].From, Seed: *seed}
+	// The engines print their progress, which mustn't get mixed in with
+	// the protocol.
+	out := os.Stdout
+	os.Stdout = os.Stderr
+	if err := s.Serve(os.Stdin, out); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
 }
 
 func main() {
-	rand.Seed(time.Now().Unix())
+	if len(os.Args) > 1 && os.Args[1] == "engine" {
+		runEngine(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "report" {
+		runReport(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "worker" {
+		runWorker(os.Args[2:])
+		return
+	}
+	configFile := flag.String("config", "", "JSON experiment config; replaces the flags below other than -seed")
+	seed := flag.Int64("seed", 0, "master seed for all games and players (0 picks one from the clock, or resumes the seed saved with earlier results)")
+	gameFlag := flag.String("games", strings.Join(defaultGames, ","), "comma separated games to run")
+	engineFlag := flag.String("engines", "Montecarlo:0-38:2", "engine axes to pair against each other, as Type:from-to[:step],...")
+	games := flag.Int("n", 100, "games per pairing when not running an SPRT")
+	concurrency := flag.Int("j", 1, "pairings to play at once")
+	output := flag.String("o", "", "directory for result files")
+	print := flag.Bool("print", true, "print every board as it is played")
+	sprtElo := flag.Float64("sprt", 0, "play each pairing until an SPRT decides whether the first engine is stronger by at least this many Elo (0 plays -n games per pairing)")
+	sprtAlpha := flag.Float64("sprt-alpha", 0.05, "SPRT false positive rate")
+	sprtBeta := flag.Float64("sprt-beta", 0.05, "SPRT false negative rate")
+	sprtMax := flag.Int("sprt-max", 2000, "most games an SPRT pairing may play before it is called inconclusive")
+	openings := flag.Int("openings", 0, "start each game from this many random plies, kept only if a shallow search finds them balanced; a pairing and its mirror share openings")
+	openingDepth := flag.Int("openings-depth", 2, "search depth used to check openings are balanced")
+	openingEval := flag.Int("openings-eval", 1, "largest score either side may have after an opening")
+	maxPlies := flag.Int("max-plies", 0, "draw games that reach this many plies (0 for no limit)")
+	adjDepth := flag.Int("adjudicate-depth", 2, "depth of the search that scores positions for engines that don't report their own evaluation")
+	winEval := flag.Int("win-eval", 0, "adjudicate a win once both engines' scores favour one side by at least this much")
+	winMoves := flag.Int("win-moves", 0, "moves by each side the -win-eval score must hold for (0 never adjudicates wins)")
+	drawEval := flag.Int("draw-eval", 0, "adjudicate a draw once both engines' scores stay within this much of even")
+	drawMoves := flag.Int("draw-moves", 0, "moves by each side the -draw-eval score must hold for (0 never adjudicates draws)")
+	clock := flag.Duration("clock", 0, "time each player has for all their moves, losing on flag fall (0 for no clock)")
+	increment := flag.Duration("increment", 0, "time added to a player's clock after each move")
+	moveTime := flag.Duration("move-time", 0, "most time any one move may take, losing on flag fall (0 for no limit)")
+	deadline := flag.Duration("deadline", 0, "most time Play waits for any one move before giving up on it (0 waits forever)")
+	deadlineFallback := flag.String("deadline-fallback", "forfeit", "what happens to a move past -deadline: forfeit or random")
+	ponder := flag.Bool("ponder", false, "let engines search on their opponent's time; only fair with a core per engine")
+	resign := flag.Bool("resign", false, "let engines resign games they judge hopeless")
+	drawOffers := flag.Bool("draw-offers", false, "let engines offer and accept draws in positions they judge dead equal")
+	external := namedFlag{}
+	flag.Var(external, "external", "run command as an engine of type name, as name=command, its parameter being seconds per move; may be repeated")
+	web := namedFlag{}
+	flag.Var(web, "http", "ask the web service at url as an engine of type name, as name=url, its parameter being seconds per move; may be repeated")
+	listen := flag.String("listen", "", "coordinate: hand games out to workers connecting on this address instead of playing them here (-j then sets pairings in flight)")
+	flag.Parse()
+
+	var config experiment.Config
+	if *configFile != "" {
+		c, err := experiment.LoadConfig(*configFile)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(2)
+		}
+		config = c
+		if *seed != 0 {
+			config.Seed = *seed
+		}
+	} else {
+		axes, err := experiment.ParseAxes(*engineFlag)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(2)
+		}
+		config = experiment.Config{
+			Seed:            *seed,
+			OutputDir:       *output,
+			Concurrency:     *concurrency,
+			GamesPerPairing: *games,
+			Print:           *print,
+			Ponder:          *ponder,
+			Resign:          *resign,
+			DrawOffers:      *drawOffers,
+			External:        external.commands(),
+			HTTP:            web,
+		}
+		for _, name := range strings.Split(*gameFlag, ",") {
+			g := experiment.GameConfig{Name: name}
+			if *openings > 0 {
+				g.Openings = &experiment.OpeningsConfig{Plies: *openings, Depth: *openingDepth, MaxEval: *openingEval}
+			}
+			config.Games = append(config.Games, g)
+		}
+		for _, axis := range axes {
+			config.Engines = append(config.Engines, experiment.AxisConfig{
+				Type: axis.Type,
+				From: axis.From,
+				To:   axis.To,
+				Step: axis.Step,
+			})
+		}
+		if *maxPlies > 0 || *winMoves > 0 || *drawMoves > 0 {
+			config.Adjudication = &experiment.AdjudicationConfig{
+				Depth:     *adjDepth,
+				WinEval:   *winEval,
+				WinMoves:  *winMoves,
+				DrawEval:  *drawEval,
+				DrawMoves: *drawMoves,
+				MaxPlies:  *maxPlies,
+			}
+		}
+		if *clock > 0 || *moveTime > 0 {
+			config.TimeControl = &experiment.TimeControlConfig{}
+			if *clock > 0 {
+				config.TimeControl.Base = clock.String()
+				config.TimeControl.Increment = increment.String()
+			}
+			if *moveTime > 0 {
+				config.TimeControl.PerMove = moveTime.String()
+			}
+		}
+		if *deadline > 0 {
+			config.Deadline = &experiment.DeadlineConfig{Limit: deadline.String(), Fallback: *deadlineFallback}
+		}
+		if *sprtElo > 0 {
+			config.SPRT = &experiment.SPRTConfig{
+				Elo0:     0,
+				Elo1:     *sprtElo,
+				Alpha:    *sprtAlpha,
+				Beta:     *sprtBeta,
+				MaxGames: *sprtMax,
+			}
+		}
+	}
+	var err error
+	if *listen != "" {
+		err = coordinate(config, *listen)
+	} else {
+		err = experiment.RunConfig(config)
+	}
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
 
-	nineWrap := func(p1, p2 string, depth1, depth2 int) int {
-		g := game.NewNineMensMorris(p1, p2, depth1, depth2)
-		return game.Play(g, true)
-	}
-	pentagoWrap := func(p1, p2 string, depth1, depth2 int) int {
-		g := game.NewPentago(p1, p2, depth1, depth2)
-		return game.Play(g, true)
-	}
-	reversiWrap := func(p1, p2 string, depth1, depth2 int) int {
-		g := game.NewReversi(p1, p2, depth1, depth2)
-		return game.Play(g, true)
-	}
-	ticWrap := func(p1, p2 string, depth1, depth2 int) int {
-		g := game.NewTicTacToe(p1, p2, depth1, depth2)
-		return game.Play(g, true)
-	}
-	martianWrap := func(p1, p2 string, depth1, depth2 int) int {
-		g := game.NewMartianChess(p1, p2, depth1, depth2)
-		return game.Play(g, true)
-	}
-	mancalaWrap := func(p1, p2 string, depth1, depth2 int) int {
-		g := game.NewMancala(p1, p2, depth1, depth2)
-		return game.Play(g, true)
-	}
-	connectWrap := func(p1, p2 string, depth1, depth2 int) int {
-		g := game.NewConnect4(p1, p2, depth1, depth2)
-		return game.Play(g, true)
-	}
-	checkersWrap := func(p1, p2 string, depth1, depth2 int) int {
-		g := game.NewCheckers(p1, p2, depth1, depth2)
-		return game.Play(g, true)
-	}
-	boxesWrap := func(p1, p2 string, depth1, depth2 int) int {
-		g := game.NewBoxes(p1, p2, depth1, depth2)
-		return game.Play(g, true)
-	}
-	//abaloneWrap := func(p1, p2 string, depth1, depth2 int) int {
-	//	g := game.NewAbalone(p1, p2, depth1, depth2)
-	//	return game.Play(g, true)
-	//}
-	wraps := []func(string, string, int, int) int{
-		ticWrap,
-		pentagoWrap,
-		mancalaWrap,
-		boxesWrap,
-		connectWrap,
-		reversiWrap,
-		checkersWrap,
-		martianWrap,
-		nineWrap,
-		//	abaloneWrap,
-	}
-	names := []string{
-		"tictactoe",
-		"pentago",
-		"mancala",
-		"boxes",
-		"connect4",
-		"reversi",
-		"checkers",
-		"martianchess",
-		"ninemensmorris",
-		//	"abalone",
-	}
-	for i, wrap := range wraps {
-		runExperiment(wrap, "Montecarlo", "Montecarlo", names[i])
+func coordinate(config experiment.Config, addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
 	}
+	defer l.Close()
+	fmt.Println("Waiting for workers on", l.Addr())
+	c := experiment.NewCoordinator()
+	go c.Serve(l)
+	err = experiment.RunConfigWith(config, c.Player)
+	c.Close()
+	// Give polling workers a moment to hear that we are done.
+	time.Sleep(time.Second)
+	return err
 }
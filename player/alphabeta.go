@@ -8,26 +8,41 @@ import (
 type AlphabetaPlayer struct {
 	Name     string
 	MaxDepth int
+	Rand     *rand.Rand
+	Nodes    *int64
+	// Conduct resigns once the search proves every move lost and offers a
+	// draw once it proves the best one drawn.
+	Conduct    *Conduct
+	Evaluation *Evaluation
 }
 
 func (p AlphabetaPlayer) GetName() string {
 	return p.Name
 }
 
+func (p AlphabetaPlayer) NodesSearched() int64 {
+	return loadNodes(p.Nodes)
+}
+
 func (p AlphabetaPlayer) GetTurn(g game.Game) game.Move {
 	moves := g.GetPossibleMoves()
 	scores := make([]int, len(moves))
+	proven := make([]bool, len(moves))
 	v := MinInt
 	alpha := MinInt
 	beta := MaxInt
 
 	if len(moves) > p.MaxDepth {
-		return moves[rand.Intn(len(moves))]
+		p.Evaluation.record(0, false)
+		return moves[p.Rand.Intn(len(moves))]
 	}
 
+	k := kernelOf(g)
+	s := &search{me: p, nodes: p.Nodes, maxDepth: p.MaxDepth, wide: true}
 	for i, move := range moves {
-		score := p.getScore(g, move, len(moves)+1, alpha, beta)
-		scores[i] = score
+		s.cut = false
+		score := k.score(s, move, len(moves)+1, alpha, beta)
+		scores[i], proven[i] = score, !s.cut
 		if score > v {
 			v = score
 		}
@@ -35,80 +50,30 @@ func (p AlphabetaPlayer) GetTurn(g game.Game) game.Move {
 			alpha = v
 		}
 		if beta <= alpha {
-			return move
-		}
-	}
-	bestScore := MinInt
-	for _, score := range scores {
-		if score >= bestScore {
-			bestScore = score
+			p.Evaluation.record(score, true)
+			return p.Conduct.judge(move, false, false)
 		}
 	}
-	var bestMoves []game.Move
+	bestScore := bestScore(scores)
+	var best []int
 	for i, score := range scores {
 		if score == bestScore {
-			bestMoves = append(bestMoves, moves[i])
+			best = append(best, i)
 		}
 	}
-	return bestMoves[rand.Intn(len(bestMoves))]
+	i := best[p.Rand.Intn(len(best))]
+	p.Evaluation.record(bestScore, true)
+	return p.Conduct.judge(moves[i], bestScore <= MinInt/2, bestScore == 0 && proven[i])
 }
 
-func (p AlphabetaPlayer) getScore(g game.Game, m game.Move, depth int, alpha int, beta int) int {
-	if depth > p.MaxDepth {
-		return g.CurrentScore(p)
-	}
-	newG := g.MakeMove(m)
-	over, winner := newG.GameOver()
-	if over {
-		if winner == p {
-			return MaxInt - depth
-		} else if winner.GetName() == "DRAW" {
-			return 0
-		} else {
-			return MinInt + depth
-		}
-	} else {
-		player := newG.GetPlayerTurn()
-		if p == player {
-			return p.getMax(newG, depth+1, alpha, beta)
-		} else {
-			return p.getMin(newG, depth+1, alpha, beta)
-		}
-	}
+func (p AlphabetaPlayer) LastEval() (int, bool) {
+	return p.Evaluation.last()
 }
 
-func (p AlphabetaPlayer) getMax(g game.Game, depth int, alpha int, beta int) int {
-	moves := g.GetPossibleMoves()
-	v := MinInt
-	for _, move := range moves {
-		score := p.getScore(g, move, depth+len(moves), alpha, beta)
-		if score > v {
-			v = score
-		}
-		if v > alpha {
-			alpha = v
-		}
-		if beta <= alpha {
-			break
-		}
-	}
-	return v
+func (p AlphabetaPlayer) Negotiate(resign, draws bool) {
+	p.Conduct.negotiate(resign, draws)
 }
 
-func (p AlphabetaPlayer) getMin(g game.Game, depth int, alpha int, beta int) int {
-	moves := g.GetPossibleMoves()
-	v := MaxInt
-	for _, move := range moves {
-		score := p.getScore(g, move, depth+len(moves), alpha, beta)
-		if score < v {
-			v = score
-		}
-		if beta < v {
-			beta = v
-		}
-		if beta <= alpha {
-			break
-		}
-	}
-	return v
+func (p AlphabetaPlayer) AcceptDraw(g game.Game) bool {
+	return p.Conduct.acceptDraw()
 }
@@ -4,139 +4,272 @@ import (
 	"fmt"
 	"github.com/damargulis/game/interfaces"
 	"math/rand"
+	"time"
 )
 
 type AlphabetaTimePlayer struct {
 	Name    string
 	MaxTime int
+	// MaxNodes, when set, bounds each move's search by nodes instead of
+	// MaxTime, so the move chosen doesn't depend on the machine.
+	MaxNodes   int
+	Rand       *rand.Rand
+	Nodes      *int64
+	Pondering  *Pondering
+	Evaluation *Evaluation
+	// Conduct resigns once the search proves every move lost and offers a
+	// draw once it has solved the position as drawn.
+	Conduct *Conduct
 }
 
 func (p AlphabetaTimePlayer) GetName() string {
 	return p.Name
 }
 
+func (p AlphabetaTimePlayer) NodesSearched() int64 {
+	return loadNodes(p.Nodes)
+}
+
+func (p AlphabetaTimePlayer) LastEval() (int, bool) {
+	return p.Evaluation.last()
+}
+
 func (p AlphabetaTimePlayer) GetTurn(g game.Game) game.Move {
+	return p.think(g, time.Duration(p.MaxTime)*time.Second)
+}
+
+// GetTimedTurn thinks for as long as the time manager gives the move.
+func (p AlphabetaTimePlayer) GetTimedTurn(g game.Game, c game.Clock) game.Move {
+	return p.think(g, moveTime(g, c))
+}
+
+func (p AlphabetaTimePlayer) think(g game.Game, limit time.Duration) game.Move {
 	moves := g.GetPossibleMoves()
 	if len(moves) == 1 {
+		p.Evaluation.record(0, false)
 		return moves[0]
 	}
-	scores := make([]int, len(moves))
-	timer := make(chan int)
-	result := make(chan int)
-
-	go sleep(float64(p.MaxTime)*1000, timer)
-	maxDepth := 0
-	move := 0
-	go p.checkMove(g, moves[move], maxDepth, result)
+	if p.MaxNodes > 0 {
+		d := newDeepening(moves)
+		deepen(g, p, p.Nodes, d, newBudget(p.MaxNodes))
+		return p.conclude(d)
+	}
+	d, pondered := p.Pondering.take(g).(*deepening)
+	if !pondered {
+		d = newDeepening(moves)
+	} else if d.decided() {
+		return p.conclude(d)
+	}
+	moves, scores, maxDepth, move := d.moves, d.scores, d.maxDepth, d.move
+	proven, proving := d.proven, d.proving
+	k := kernelOf(g)
+	timer := make(chan int, 1)
+	result := make(chan scored)
+	crash := new(relay)
+
+	deadline := time.Now().Add(limit)
+	go sleep(limit, timer)
+	go checkMove(k, p, p.Nodes, moves[move], maxDepth, result, crash)
 	move++
 	if move >= len(moves) {
 		move = 0
 		maxDepth++
+		proven, proving = proving, true
 	}
+search:
 	for {
 		select {
 		case r := <-result:
-			if r == MaxInt {
-				return moves[move]
-			} else if r == MinInt {
+			crash.rethrow()
+			proving = proving && r.proven
+			if r.score == MaxInt {
+				p.Evaluation.record(MaxInt, true)
+				return p.Conduct.judge(moves[move], false, false)
+			} else if r.score == MinInt {
 				moves = append(moves[:move], moves[move+1:]...)
 				scores = append(scores[:move], scores[move+1:]...)
 				if len(moves) == 1 {
-					return moves[0]
-				}
-				if move >= len(moves) {
-					move = 0
-					maxDepth++
+					p.Evaluation.record(0, false)
+					return p.Conduct.judge(moves[0], false, false)
 				}
-				go p.checkMove(g, moves[move], maxDepth, result)
 			} else {
-				scores[move] = r
+				scores[move] = r.score
 				move++
-				if move >= len(moves) {
-					move = 0
-					maxDepth++
-				}
-				go p.checkMove(g, moves[move], maxDepth, result)
 			}
-		case <-timer:
-			fmt.Printf("Max depth: %v\n", maxDepth)
-			bestScore := MinInt
-			for _, score := range scores {
-				if score > bestScore {
-					bestScore = score
-				}
+			if move >= len(moves) {
+				move = 0
+				maxDepth++
+				proven, proving = proving, true
 			}
-			var bestMoves []game.Move
-			for i, score := range scores {
-				if score == bestScore {
-					bestMoves = append(bestMoves, moves[i])
-				}
+			if past(deadline) {
+				break search
 			}
-			return bestMoves[rand.Intn(len(bestMoves))]
+			go checkMove(k, p, p.Nodes, moves[move], maxDepth, result, crash)
+		case <-timer:
+			break search
 		}
 	}
+	fmt.Printf("Max depth: %v\n", maxDepth)
+	// Until the first depth is done some moves have no score yet.
+	bestScore := bestScore(scores)
+	p.Evaluation.record(bestScore, maxDepth > 0)
+	bestMoves := bestScoring(moves, scores)
+	return p.Conduct.judge(bestMoves[p.Rand.Intn(len(bestMoves))], bestScore <= MinInt/2, bestScore == 0 && proven)
 }
 
-func (p AlphabetaTimePlayer) checkMove(g game.Game, m game.Move, maxDepth int, r chan int) {
-	r <- p.getScore(g, m, 0, MinInt, MaxInt, maxDepth)
+// conclude plays the move d has settled on, recording how it was scored.
+func (p AlphabetaTimePlayer) conclude(d *deepening) game.Move {
+	score, ok := d.score()
+	p.Evaluation.record(score, ok)
+	return p.Conduct.judge(d.best(p.Rand), ok && score <= MinInt/2, ok && score == 0 && d.proven)
 }
 
-func (p AlphabetaTimePlayer) getScore(g game.Game, m game.Move, depth, alpha, beta, maxDepth int) int {
-	if depth > maxDepth {
-		return g.CurrentScore(p)
+// deepening is how far an iterative deepening search has got: each move's
+// score at the deepest level it has finished, and the move to search next.
+// proven is whether every search of the last depth it finished reached the
+// end of each line, so that those scores are exact, and proving is the same
+// for the depth under way.
+type deepening struct {
+	moves    []game.Move
+	scores   []int
+	maxDepth int
+	move     int
+	won      bool
+	proven   bool
+	proving  bool
+}
+
+func newDeepening(moves []game.Move) *deepening {
+	return &deepening{moves: moves, scores: make([]int, len(moves)), proving: true}
+}
+
+func (d *deepening) decided() bool {
+	return d.won || len(d.moves) == 1
+}
+
+// score is what the search makes of the move best plays, if it has finished
+// a depth.
+func (d *deepening) score() (int, bool) {
+	if d.won {
+		return MaxInt, true
+	} else if len(d.moves) == 1 {
+		return 0, false
 	}
-	newG := g.MakeMove(m)
-	over, winner := newG.GameOver()
-	if over {
-		if winner == p {
-			return MaxInt
-		} else if winner.GetName() == "DRAW" {
-			return 0
-		} else {
-			return MinInt
-		}
-	} else {
-		player := newG.GetPlayerTurn()
-		if p == player {
-			return p.getMax(newG, depth+1, alpha, beta, maxDepth)
-		} else {
-			return p.getMin(newG, depth+1, alpha, beta, maxDepth)
-		}
+	return bestScore(d.scores), d.maxDepth > 0
+}
+
+// leaders is the moves d scores best so far.
+func (d *deepening) leaders() []game.Move {
+	if d.won {
+		return d.moves[d.move : d.move+1]
 	}
+	return bestScoring(d.moves, d.scores)
 }
 
-func (p AlphabetaTimePlayer) getMax(g game.Game, depth int, alpha int, beta int, maxDepth int) int {
-	moves := g.GetPossibleMoves()
-	v := MinInt
-	for _, move := range moves {
-		score := p.getScore(g, move, depth, alpha, beta, maxDepth)
-		if score > v {
-			v = score
+func (d *deepening) best(r *rand.Rand) game.Move {
+	leaders := d.leaders()
+	if d.decided() {
+		return leaders[0]
+	}
+	return leaders[r.Intn(len(leaders))]
+}
+
+// deepen carries on d for me one move at a time, as the timed searches do
+// but in order, until b runs out or the move is decided. A move whose
+// search was cut short keeps the score from the depth before.
+func deepen(g game.Game, me game.Player, nodes *int64, d *deepening, b *budget) {
+	k := kernelOf(g)
+	for !d.decided() {
+		r := getScore(k, me, nodes, d.moves[d.move], d.maxDepth, b)
+		if b.empty() {
+			return
 		}
-		if v > alpha {
-			alpha = v
+		d.proving = d.proving && r.proven
+		if r.score == MaxInt {
+			d.won = true
+			return
+		} else if r.score == MinInt {
+			d.moves = append(d.moves[:d.move], d.moves[d.move+1:]...)
+			d.scores = append(d.scores[:d.move], d.scores[d.move+1:]...)
+		} else {
+			d.scores[d.move] = r.score
+			d.move++
 		}
-		if beta <= alpha {
-			break
+		if d.move >= len(d.moves) {
+			d.move = 0
+			d.maxDepth++
+			d.proven, d.proving = d.proving, true
 		}
 	}
-	return v
 }
 
-func (p AlphabetaTimePlayer) getMin(g game.Game, depth int, alpha int, beta int, maxDepth int) int {
-	moves := g.GetPossibleMoves()
-	v := MaxInt
-	for _, move := range moves {
-		score := p.getScore(g, move, depth, alpha, beta, maxDepth)
-		if score < v {
-			v = score
+// StartPondering guesses the opponent's reply and deepens the search on
+// the position it leads to until they move.
+func (p AlphabetaTimePlayer) StartPondering(g game.Game) {
+	if p.Pondering == nil || p.MaxNodes > 0 {
+		return
+	}
+	p.Pondering.start(func(b *budget) (game.Game, interface{}) {
+		pos := p.predictReply(g, b)
+		if pos == nil {
+			return nil, nil
 		}
-		if beta < v {
-			beta = v
+		d := newDeepening(pos.GetPossibleMoves())
+		deepen(pos, p, p.Nodes, d, b)
+		return pos, d
+	})
+}
+
+func (p AlphabetaTimePlayer) OpponentMoved(g game.Game, m game.Move) {
+	p.Pondering.opponentMoved(g)
+}
+
+func (p AlphabetaTimePlayer) StopPondering() {
+	p.Pondering.halt()
+}
+
+// predictReply takes the opponent's likely move in g to be the one a
+// shallow search scores worst for p, returning the position it leads to
+// if p is to move there.
+func (p AlphabetaTimePlayer) predictReply(g game.Game, b *budget) game.Game {
+	replies := g.GetPossibleMoves()
+	k := kernelOf(g)
+	best, bestScore := 0, MaxInt
+	for i, m := range replies {
+		score := getScore(k, p, p.Nodes, m, 1, b).score
+		if b.empty() {
+			return nil
 		}
-		if beta <= alpha {
-			break
+		if score < bestScore {
+			best, bestScore = i, score
 		}
 	}
-	return v
+	pos := g.MakeMove(replies[best])
+	if over, _ := pos.GameOver(); over || pos.GetPlayerTurn() != p {
+		return nil
+	}
+	return pos
+}
+
+// checkMove sends m's score on r, for a timed search running it on a
+// goroutine of its own.
+func checkMove(k Kernel, me game.Player, nodes *int64, m game.Move, maxDepth int, r chan scored, crash *relay) {
+	defer crash.catch(func() { r <- scored{} })
+	r <- getScore(k, me, nodes, m, maxDepth, nil)
+}
+
+// getScore is m's score for me in an iterative deepening search's pass to
+// maxDepth.
+func getScore(k Kernel, me game.Player, nodes *int64, m game.Move, maxDepth int, b *budget) scored {
+	s := &search{me: me, nodes: nodes, maxDepth: maxDepth, budget: b}
+	score := k.score(s, m, 0, MinInt, MaxInt)
+	return scored{score, !s.cut}
+}
+
+func (p AlphabetaTimePlayer) Negotiate(resign, draws bool) {
+	p.Conduct.negotiate(resign, draws)
+}
+
+func (p AlphabetaTimePlayer) AcceptDraw(g game.Game) bool {
+	return p.Conduct.acceptDraw()
 }
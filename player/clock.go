@@ -0,0 +1,34 @@
+package player
+
+import (
+	"github.com/damargulis/game/interfaces"
+	"time"
+)
+
+// expectedMoves is how many moves each side is assumed to make in a game
+// when sharing out the clock, and minMovesToGo the fewest still to come.
+const (
+	expectedMoves = 40
+	minMovesToGo  = 10
+)
+
+// moveTime budgets the next move: an even share of the remaining time over
+// the moves expected to be left plus most of the increment, never more than
+// half of what is left or most of a per move limit.
+func moveTime(g game.Game, c game.Clock) time.Duration {
+	var t time.Duration
+	if c.Remaining > 0 {
+		movesToGo := expectedMoves - g.GetRound()/2
+		if movesToGo < minMovesToGo {
+			movesToGo = minMovesToGo
+		}
+		t = c.Remaining/time.Duration(movesToGo) + c.Increment*3/4
+		if t > c.Remaining/2 {
+			t = c.Remaining / 2
+		}
+	}
+	if c.PerMove > 0 && (t == 0 || t > c.PerMove*3/4) {
+		t = c.PerMove * 3 / 4
+	}
+	return t
+}
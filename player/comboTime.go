@@ -4,213 +4,166 @@ import (
 	"fmt"
 	"github.com/damargulis/game/interfaces"
 	"math/rand"
+	"time"
 )
 
 type ComboTimePlayer struct {
 	Name    string
 	MaxTime int
+	// MaxNodes, when set, splits that many nodes between the two stages
+	// of each move rather than MaxTime.
+	MaxNodes int
+	Rand     *rand.Rand
+	Nodes    *int64
+	// Conduct judges the position by the playouts that pick the move, as
+	// MonteCarloTimePlayer's does.
+	Conduct *Conduct
 }
 
 func (p ComboTimePlayer) GetName() string {
 	return p.Name
 }
 
+func (p ComboTimePlayer) NodesSearched() int64 {
+	return loadNodes(p.Nodes)
+}
+
 func (p ComboTimePlayer) GetTurn(g game.Game) game.Move {
+	return p.think(g, time.Duration(p.MaxTime)*time.Second)
+}
+
+// GetTimedTurn thinks for as long as the time manager gives the move.
+func (p ComboTimePlayer) GetTimedTurn(g game.Game, c game.Clock) game.Move {
+	return p.think(g, moveTime(g, c))
+}
+
+func (p ComboTimePlayer) think(g game.Game, limit time.Duration) game.Move {
 	moves := g.GetPossibleMoves()
 	if len(moves) == 1 {
 		return moves[0]
 	}
-	moves = p.alphaStage(g, moves, float64(p.MaxTime)/float64(2)*1000)
-	if len(moves) == 1 {
-		return moves[0]
+	var s *playoutStats
+	if p.MaxNodes > 0 {
+		d := newDeepening(moves)
+		deepen(g, p, p.Nodes, d, newBudget(p.MaxNodes/2))
+		moves = d.leaders()
+		if len(moves) == 1 {
+			return p.Conduct.judge(moves[0], false, false)
+		}
+		s = playoutNodes(g, p, p.Nodes, moves, p.Rand, newBudget(p.MaxNodes-p.MaxNodes/2))
+	} else {
+		moves = p.alphaStage(g, moves, limit/2)
+		if len(moves) == 1 {
+			return p.Conduct.judge(moves[0], false, false)
+		}
+		s = p.montecarloStage(g, moves, limit/2)
 	}
-	moves = p.montecarloStage(g, moves, float64(p.MaxTime)/float64(2)*1000)
-	return moves[rand.Intn(len(moves))]
+	bestMoves := bestRated(moves, s.wins, s.attempts)
+	return p.Conduct.judgePlayouts(bestMoves[p.Rand.Intn(len(bestMoves))], s.wins, s.attempts, s.draws)
 }
 
-func (p ComboTimePlayer) montecarloStage(g game.Game, moves []game.Move, time float64) []game.Move {
-	wins := make([]float64, len(moves))
-	attempts := make([]int, len(moves))
+func (p ComboTimePlayer) montecarloStage(g game.Game, moves []game.Move, limit time.Duration) *playoutStats {
+	s := newPlayoutStats(len(moves))
+	wins, attempts, draws := s.wins, s.attempts, s.draws
 
-	timer := make(chan int)
+	timer := make(chan int, 1)
 	result := make(chan float64)
+	simRand := childRand(p.Rand)
+	crash := new(relay)
+	k := kernelOf(g)
 
-	move := rand.Intn(len(moves))
+	move := p.Rand.Intn(len(moves))
 	attempts[move]++
-	newG := g.MakeMove(moves[move])
-	go sleep(time, timer)
-	go p.runSimulation(newG, result, 0)
+	countNode(p.Nodes)
+	deadline := time.Now().Add(limit)
+	go sleep(limit, timer)
+	go p.runSimulation(k, moves[move], simRand, result, crash)
 	iters := 0
+search:
 	for {
 		select {
 		case r := <-result:
+			crash.rethrow()
 			iters++
 			wins[move] += r
-			attempts[move]++
-			move = rand.Intn(len(moves))
-			newG = g.MakeMove(moves[move])
-			go p.runSimulation(newG, result, 0)
-		case <-timer:
-			fmt.Printf("Number of iterations: %v\n", iters)
-			bestScore := float64(MinInt)
-			scores := make([]float64, len(moves))
-			for i := range moves {
-				if attempts[i] > 0 {
-					scores[i] = wins[i] / float64(attempts[i])
-				} else {
-					scores[i] = 0
-				}
-				if scores[i] >= bestScore {
-					bestScore = scores[i]
-				}
+			if r == 0 {
+				draws[move]++
 			}
-			var bestMoves []game.Move
-			for i, score := range scores {
-				if score == bestScore {
-					bestMoves = append(bestMoves, moves[i])
-				}
+			attempts[move]++
+			if past(deadline) {
+				break search
 			}
-			return bestMoves
+			move = p.Rand.Intn(len(moves))
+			countNode(p.Nodes)
+			go p.runSimulation(k, moves[move], simRand, result, crash)
+		case <-timer:
+			break search
 		}
 	}
+	fmt.Printf("Number of iterations: %v\n", iters)
+	return s
 }
 
-func (p ComboTimePlayer) runSimulation(g game.Game, result chan float64, depth int) {
-	over, winner := g.GameOver()
-	if over {
-		if winner == p {
-			result <- float64(MaxInt-depth) / float64(MaxInt)
-		} else if winner.GetName() == "DRAW" {
-			result <- 0
-		} else {
-			result <- float64(MinInt+depth) / float64(MaxInt)
-		}
-	} else {
-		moves := g.GetPossibleMoves()
-		move := moves[rand.Intn(len(moves))]
-		newG := g.MakeMove(move)
-		p.runSimulation(newG, result, depth+1)
-	}
+func (p ComboTimePlayer) runSimulation(k Kernel, m game.Move, r *rand.Rand, result chan float64, crash *relay) {
+	defer crash.catch(func() { result <- 0 })
+	score, _ := simulate(k, m, p, r, nil, p.Nodes)
+	result <- score
 }
 
-func (p ComboTimePlayer) alphaStage(g game.Game, moves []game.Move, time float64) []game.Move {
+func (p ComboTimePlayer) alphaStage(g game.Game, moves []game.Move, limit time.Duration) []game.Move {
 	scores := make([]int, len(moves))
-	timer := make(chan int)
-	result := make(chan int)
+	timer := make(chan int, 1)
+	result := make(chan scored)
+	crash := new(relay)
+	k := kernelOf(g)
 
-	go sleep(time, timer)
+	deadline := time.Now().Add(limit)
+	go sleep(limit, timer)
 	maxDepth := 0
 	move := 0
-	go p.checkMove(g, moves[move], maxDepth, result)
+	go checkMove(k, p, p.Nodes, moves[move], maxDepth, result, crash)
 	move++
 	if move >= len(moves) {
 		move = 0
 		maxDepth++
 	}
+search:
 	for {
 		select {
 		case r := <-result:
-			if r == MaxInt {
+			crash.rethrow()
+			if r.score == MaxInt {
 				return []game.Move{moves[move]}
-			} else if r == MinInt {
+			} else if r.score == MinInt {
 				moves = append(moves[:move], moves[move+1:]...)
 				scores = append(scores[:move], scores[move+1:]...)
 				if len(moves) == 1 {
 					return moves
 				}
-				if move >= len(moves) {
-					move = 0
-					maxDepth++
-				}
-				go p.checkMove(g, moves[move], maxDepth, result)
 			} else {
-				scores[move] = r
+				scores[move] = r.score
 				move++
-				if move >= len(moves) {
-					move = 0
-					maxDepth++
-				}
-				go p.checkMove(g, moves[move], maxDepth, result)
 			}
-		case <-timer:
-			fmt.Printf("Max depth: %v\n", maxDepth)
-			bestScore := MinInt
-			for _, score := range scores {
-				if score > bestScore {
-					bestScore = score
-				}
+			if move >= len(moves) {
+				move = 0
+				maxDepth++
 			}
-			var bestMoves []game.Move
-			for i, score := range scores {
-				if score == bestScore {
-					bestMoves = append(bestMoves, moves[i])
-				}
+			if past(deadline) {
+				break search
 			}
-			return bestMoves
-		}
-	}
-}
-
-func (p ComboTimePlayer) checkMove(g game.Game, m game.Move, maxDepth int, r chan int) {
-	r <- p.getScore(g, m, 0, MinInt, MaxInt, maxDepth)
-}
-
-func (p ComboTimePlayer) getScore(g game.Game, m game.Move, depth, alpha, beta, maxDepth int) int {
-	if depth > maxDepth {
-		return g.CurrentScore(p)
-	}
-	newG := g.MakeMove(m)
-	over, winner := newG.GameOver()
-	if over {
-		if winner == p {
-			return MaxInt
-		} else if winner.GetName() == "DRAW" {
-			return 0
-		} else {
-			return MinInt
-		}
-	} else {
-		player := newG.GetPlayerTurn()
-		if p == player {
-			return p.getMax(newG, depth+1, alpha, beta, maxDepth)
-		} else {
-			return p.getMin(newG, depth+1, alpha, beta, maxDepth)
+			go checkMove(k, p, p.Nodes, moves[move], maxDepth, result, crash)
+		case <-timer:
+			break search
 		}
 	}
+	fmt.Printf("Max depth: %v\n", maxDepth)
+	return bestScoring(moves, scores)
 }
 
-func (p ComboTimePlayer) getMax(g game.Game, depth int, alpha int, beta int, maxDepth int) int {
-	moves := g.GetPossibleMoves()
-	v := MinInt
-	for _, move := range moves {
-		score := p.getScore(g, move, depth, alpha, beta, maxDepth)
-		if score > v {
-			v = score
-		}
-		if v > alpha {
-			alpha = v
-		}
-		if beta <= alpha {
-			break
-		}
-	}
-	return v
+func (p ComboTimePlayer) Negotiate(resign, draws bool) {
+	p.Conduct.negotiate(resign, draws)
 }
 
-func (p ComboTimePlayer) getMin(g game.Game, depth int, alpha int, beta int, maxDepth int) int {
-	moves := g.GetPossibleMoves()
-	v := MaxInt
-	for _, move := range moves {
-		score := p.getScore(g, move, depth, alpha, beta, maxDepth)
-		if score < v {
-			v = score
-		}
-		if beta < v {
-			beta = v
-		}
-		if beta <= alpha {
-			break
-		}
-	}
-	return v
+func (p ComboTimePlayer) AcceptDraw(g game.Game) bool {
+	return p.Conduct.acceptDraw()
 }
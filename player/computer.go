@@ -7,6 +7,7 @@ import (
 
 type ComputerPlayer struct {
 	Name string
+	Rand *rand.Rand
 }
 
 func (p ComputerPlayer) GetName() string {
@@ -15,5 +16,5 @@ func (p ComputerPlayer) GetName() string {
 
 func (p ComputerPlayer) GetTurn(g game.Game) game.Move {
 	moves := g.GetPossibleMoves()
-	return moves[rand.Intn(len(moves))]
+	return moves[p.Rand.Intn(len(moves))]
 }
@@ -0,0 +1,77 @@
+package player
+
+import (
+	"github.com/damargulis/game/interfaces"
+)
+
+// conductMoves is how many of its moves in a row a player must judge a game
+// settled before resigning or offering a draw.
+const conductMoves = 3
+
+// A playout player judges a position hopeless when even its best move
+// scores hopelessRate or worse, and dead equal when deadDrawRate of its
+// best move's playouts were drawn.
+const (
+	hopelessRate = -0.9
+	deadDrawRate = 0.9
+)
+
+// Conduct is how a search player ends games it thinks are settled: it
+// resigns after conductMoves hopeless moves in a row, offers a draw after
+// as many dead equal ones, and accepts a draw when its last move was
+// either. A nil Conduct plays every game out.
+type Conduct struct {
+	resign   bool
+	draws    bool
+	hopeless int
+	level    int
+}
+
+func (c *Conduct) negotiate(resign, draws bool) {
+	if c != nil {
+		c.resign, c.draws = resign, draws
+		c.hopeless, c.level = 0, 0
+	}
+}
+
+// judge records how move's search judged the position, returning what to
+// play in its place.
+func (c *Conduct) judge(move game.Move, hopeless, dead bool) game.Move {
+	if c == nil {
+		return move
+	}
+	c.hopeless = streak(c.hopeless, hopeless)
+	c.level = streak(c.level, dead)
+	if c.resign && c.hopeless >= conductMoves {
+		return game.Resign{}
+	}
+	if c.draws && c.level >= conductMoves {
+		return game.DrawOffer{Move: move}
+	}
+	return move
+}
+
+// judgePlayouts judges a playout search from each move's wins, attempts
+// and drawn playouts: hopeless if even the best rated move is, dead equal
+// if nearly all of a best rated move's playouts were drawn.
+func (c *Conduct) judgePlayouts(move game.Move, wins []float64, attempts, draws []int) game.Move {
+	best := bestRate(wins, attempts)
+	dead := false
+	for i := range wins {
+		if attempts[i] > 0 && rate(wins[i], attempts[i]) == best && float64(draws[i]) >= deadDrawRate*float64(attempts[i]) {
+			dead = true
+		}
+	}
+	return c.judge(move, best <= hopelessRate, dead)
+}
+
+func (c *Conduct) acceptDraw() bool {
+	return c != nil && (c.hopeless > 0 || c.level > 0)
+}
+
+func streak(n int, ok bool) int {
+	if ok {
+		return n + 1
+	}
+	return 0
+}
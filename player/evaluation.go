@@ -0,0 +1,22 @@
+package player
+
+// Evaluation keeps the score a search player's last move was given by its
+// own search, which it reports as a game.Evaluator. A nil Evaluation keeps
+// nothing.
+type Evaluation struct {
+	score int
+	ok    bool
+}
+
+func (e *Evaluation) record(score int, ok bool) {
+	if e != nil {
+		e.score, e.ok = score, ok
+	}
+}
+
+func (e *Evaluation) last() (int, bool) {
+	if e == nil {
+		return 0, false
+	}
+	return e.score, e.ok
+}
@@ -0,0 +1,51 @@
+package player
+
+import (
+	"github.com/damargulis/game/interfaces"
+	"math/rand"
+)
+
+// BoxedKernel is the Kernel a game without one of its own gets, to compare
+// with the game's own.
+func BoxedKernel(g game.Game) Kernel {
+	return NewKernel[game.Boxed, game.Move](game.Boxed{Game: g})
+}
+
+func KernelOf(g game.Game) Kernel {
+	return kernelOf(g)
+}
+
+// Score is m's alphabeta score for me to maxDepth, as the iterative
+// deepening players search it.
+func Score(k Kernel, me game.Player, m game.Move, maxDepth int, nodes *int64) int {
+	return k.score(&search{me: me, nodes: nodes, maxDepth: maxDepth}, m, 0, MinInt, MaxInt)
+}
+
+func Playout(k Kernel, m game.Move, r *rand.Rand, nodes *int64) game.Player {
+	winner, _, _ := k.playout(m, r, nil, nodes)
+	return winner
+}
+
+// Predicted stops pondering and returns the position it predicted, leaving
+// the opponent's move to hit or miss it.
+func Predicted(s *Pondering) game.Game {
+	s.stop.Store(true)
+	<-s.done
+	return s.predicted
+}
+
+// Pondered is how much work pondering holds for the next turn: the depth
+// the search reached or the playouts it played.
+func Pondered(s *Pondering) int {
+	switch w := s.work.(type) {
+	case *deepening:
+		return w.maxDepth
+	case *playoutStats:
+		n := 0
+		for _, a := range w.attempts {
+			n += a
+		}
+		return n
+	}
+	return 0
+}
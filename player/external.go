@@ -0,0 +1,188 @@
+package player
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/damargulis/game/interfaces"
+	"io"
+	"os"
+	"os/exec"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// externalGrace is how long past its budget an external engine may take to
+// answer before it is given up on.
+const externalGrace = 5 * time.Second
+
+// ExternalPlayer plays the moves another program chooses, talking to it one
+// line at a time over its stdin and stdout:
+//
+//	> newgame <game> <seat>    a game starts, seat being 1 or 2
+//	> position <move> ...      the moves played so far
+//	> legal <move> ...         the moves that can be played now
+//	> go <ms>                  think for up to ms milliseconds
+//	< bestmove <move>          play one of the legal moves
+//	> quit                     the game is over
+//
+// A game is named as in game.Games, and a move written as MoveText writes
+// it. Other lines from the program, such as "info ...", are ignored. The
+// program is started for the player's first move and told to quit by Close;
+// Play hands the player the moves so far and closes it when the game ends.
+// A program that exits, stalls or answers with a move that isn't legal
+// panics the player. The command is kept with the running program, so
+// players stay comparable, as games compare them with ==.
+type ExternalPlayer struct {
+	Name    string
+	MaxTime int
+	engine  *engine
+}
+
+func NewExternalPlayer(name string, command []string, maxTime int) ExternalPlayer {
+	return ExternalPlayer{Name: name, MaxTime: maxTime, engine: &engine{command: command}}
+}
+
+type engine struct {
+	command []string
+	cmd     *exec.Cmd
+	in      io.WriteCloser
+	lines   chan string
+	history []game.Move
+}
+
+// MoveText writes m as the game prints it with its spaces made commas, so
+// a tic-tac-toe square is {1,2}.
+func MoveText(m game.Move) string {
+	return strings.Join(strings.Fields(fmt.Sprint(m)), ",")
+}
+
+func (p ExternalPlayer) GetName() string {
+	return p.Name
+}
+
+func (p ExternalPlayer) SetHistory(moves []game.Move) {
+	p.engine.history = moves
+}
+
+func (p ExternalPlayer) GetTurn(g game.Game) game.Move {
+	return p.think(g, time.Duration(p.MaxTime)*time.Second)
+}
+
+// GetTimedTurn gives the program as long as the time manager gives the
+// move.
+func (p ExternalPlayer) GetTimedTurn(g game.Game, c game.Clock) game.Move {
+	return p.think(g, moveTime(g, c))
+}
+
+func (p ExternalPlayer) think(g game.Game, limit time.Duration) game.Move {
+	e := p.engine
+	if e.cmd == nil {
+		seat := 1
+		if p.Name != "Player 1" {
+			seat = 2
+		}
+		e.start()
+		e.send("newgame %v %v", gameName(g), seat)
+	}
+	moves := g.GetPossibleMoves()
+	e.send("position%v", texts(e.history))
+	e.send("legal%v", texts(moves))
+	e.send("go %v", limit.Milliseconds())
+	reply := e.bestMove(limit + externalGrace)
+	for _, m := range moves {
+		if MoveText(m) == reply {
+			return m
+		}
+	}
+	panic(fmt.Errorf("%v played %q, which is not a legal move", e.command[0], reply))
+}
+
+// Close tells the program to quit, killing it if it doesn't.
+func (p ExternalPlayer) Close() error {
+	e := p.engine
+	if e == nil || e.cmd == nil {
+		return nil
+	}
+	fmt.Fprintln(e.in, "quit")
+	e.in.Close()
+	exited := make(chan error, 1)
+	go func() {
+		exited <- e.cmd.Wait()
+	}()
+	select {
+	case <-exited:
+	case <-time.After(externalGrace):
+		e.cmd.Process.Kill()
+		<-exited
+	}
+	e.cmd = nil
+	return nil
+}
+
+func (e *engine) start() {
+	cmd := exec.Command(e.command[0], e.command[1:]...)
+	cmd.Stderr = os.Stderr
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		panic(err)
+	}
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		panic(err)
+	}
+	if err := cmd.Start(); err != nil {
+		panic(err)
+	}
+	e.cmd, e.in, e.lines = cmd, in, make(chan string)
+	go func(lines chan string) {
+		s := bufio.NewScanner(out)
+		for s.Scan() {
+			lines <- s.Text()
+		}
+		close(lines)
+	}(e.lines)
+}
+
+func (e *engine) send(format string, args ...interface{}) {
+	if _, err := fmt.Fprintf(e.in, format+"\n", args...); err != nil {
+		panic(fmt.Errorf("%v: %v", e.cmd.Path, err))
+	}
+}
+
+// bestMove waits up to limit for the program's move.
+func (e *engine) bestMove(limit time.Duration) string {
+	timeout := time.After(limit)
+	for {
+		select {
+		case line, ok := <-e.lines:
+			if !ok {
+				panic(fmt.Errorf("%v exited without playing a move", e.cmd.Path))
+			}
+			if move, found := strings.CutPrefix(line, "bestmove "); found {
+				return strings.TrimSpace(move)
+			}
+		case <-timeout:
+			panic(fmt.Errorf("%v played no move within %v", e.cmd.Path, limit))
+		}
+	}
+}
+
+func texts(moves []game.Move) string {
+	var b strings.Builder
+	for _, m := range moves {
+		b.WriteString(" ")
+		b.WriteString(MoveText(m))
+	}
+	return b.String()
+}
+
+// gameName is the name game.Games knows g by, its type's name in lower
+// case.
+func gameName(g game.Game) string {
+	t := reflect.TypeOf(g)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return strings.ToLower(t.Name())
+}
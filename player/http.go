@@ -0,0 +1,110 @@
+package player
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/damargulis/game/interfaces"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// httpGrace is how long past its budget a web service may take to answer.
+const httpGrace = 2 * time.Second
+
+// HTTPPlayer plays the moves a web service chooses. For each move it POSTs
+//
+//	{"game": "tictactoe", "seat": 1, "moves": ["{1,1}"], "legal": ["{0,0}", ...], "time_ms": 1000}
+//
+// to URL, with the game named as in game.Games, the moves played so far
+// ([] before the first) and the legal moves written as MoveText writes them,
+// and the time the service has to choose. It plays the "move" of a {"move": "{0,0}"} reply. A service
+// that fails, runs out of time or answers with a move that isn't legal has
+// a random legal move played for it instead.
+type HTTPPlayer struct {
+	Name    string
+	URL     string
+	MaxTime int
+	Rand    *rand.Rand
+	history *[]game.Move
+}
+
+func NewHTTPPlayer(name string, url string, maxTime int, r *rand.Rand) HTTPPlayer {
+	return HTTPPlayer{Name: name, URL: url, MaxTime: maxTime, Rand: r, history: new([]game.Move)}
+}
+
+type moveRequest struct {
+	Game   string   `json:"game"`
+	Seat   int      `json:"seat"`
+	Moves  []string `json:"moves"`
+	Legal  []string `json:"legal"`
+	TimeMS int64    `json:"time_ms"`
+}
+
+type moveReply struct {
+	Move string `json:"move"`
+}
+
+func (p HTTPPlayer) GetName() string {
+	return p.Name
+}
+
+func (p HTTPPlayer) SetHistory(moves []game.Move) {
+	*p.history = moves
+}
+
+func (p HTTPPlayer) GetTurn(g game.Game) game.Move {
+	return p.ask(g, time.Duration(p.MaxTime)*time.Second)
+}
+
+// GetTimedTurn gives the service as long as the time manager gives the move.
+func (p HTTPPlayer) GetTimedTurn(g game.Game, c game.Clock) game.Move {
+	return p.ask(g, moveTime(g, c))
+}
+
+func (p HTTPPlayer) ask(g game.Game, limit time.Duration) game.Move {
+	moves := g.GetPossibleMoves()
+	m, err := p.request(g, moves, limit)
+	if err != nil {
+		fmt.Printf("%v: %v; playing a random move\n", p.Name, err)
+		return moves[p.Rand.Intn(len(moves))]
+	}
+	return m
+}
+
+func (p HTTPPlayer) request(g game.Game, moves []game.Move, limit time.Duration) (game.Move, error) {
+	req := moveRequest{Game: gameName(g), Seat: 1, Moves: []string{}, TimeMS: limit.Milliseconds()}
+	if p.Name != "Player 1" {
+		req.Seat = 2
+	}
+	for _, m := range *p.history {
+		req.Moves = append(req.Moves, MoveText(m))
+	}
+	for _, m := range moves {
+		req.Legal = append(req.Legal, MoveText(m))
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{Timeout: limit + httpGrace}
+	resp, err := client.Post(p.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("service answered %v", resp.Status)
+	}
+	var reply moveReply
+	if err := json.NewDecoder(resp.Body).Decode(&reply); err != nil {
+		return nil, err
+	}
+	for i, text := range req.Legal {
+		if text == reply.Move {
+			return moves[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%q is not a legal move", reply.Move)
+}
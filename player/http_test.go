@@ -0,0 +1,117 @@
+package player_test
+
+import (
+	"encoding/json"
+	"github.com/damargulis/game/game"
+	interfaces "github.com/damargulis/game/interfaces"
+	"github.com/damargulis/game/player"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// moveService is a web service that answers each request with reply,
+// keeping the bodies it was sent.
+type moveService struct {
+	bodies []string
+	reply  func(w http.ResponseWriter, legal []string)
+}
+
+func (s *moveService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.bodies = append(s.bodies, string(body))
+	var req struct {
+		Legal []string `json:"legal"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.reply(w, req.Legal)
+}
+
+func isLegal(g interfaces.Game, m interfaces.Move) bool {
+	for _, legal := range g.GetPossibleMoves() {
+		if legal == m {
+			return true
+		}
+	}
+	return false
+}
+
+func TestHTTPPlayerPlaysTheServicesMove(t *testing.T) {
+	s := &moveService{reply: func(w http.ResponseWriter, legal []string) {
+		json.NewEncoder(w).Encode(map[string]string{"move": legal[len(legal)-1]})
+	}}
+	server := httptest.NewServer(s)
+	defer server.Close()
+
+	g := game.NewTicTacToe("Computer", "Computer", 0, 0, 1)
+	p := player.NewHTTPPlayer("Player 1", server.URL, 1, rand.New(rand.NewSource(1)))
+	first := g.GetPossibleMoves()[0]
+	p.SetHistory([]interfaces.Move{first})
+	after := g.MakeMove(first)
+	moves := after.GetPossibleMoves()
+	if got, want := p.GetTurn(after), moves[len(moves)-1]; got != want {
+		t.Errorf("GetTurn() = %v, want the service's move %v", got, want)
+	}
+	if len(s.bodies) != 1 {
+		t.Fatalf("service got %v requests, want 1", len(s.bodies))
+	}
+	if want := `"moves":["` + player.MoveText(first) + `"]`; !strings.Contains(s.bodies[0], want) {
+		t.Errorf("request %v, want it to contain %v", s.bodies[0], want)
+	}
+}
+
+func TestHTTPPlayerSendsAnEmptyHistory(t *testing.T) {
+	s := &moveService{reply: func(w http.ResponseWriter, legal []string) {
+		json.NewEncoder(w).Encode(map[string]string{"move": legal[0]})
+	}}
+	server := httptest.NewServer(s)
+	defer server.Close()
+
+	g := game.NewTicTacToe("Computer", "Computer", 0, 0, 1)
+	p := player.NewHTTPPlayer("Player 1", server.URL, 1, rand.New(rand.NewSource(1)))
+	p.GetTurn(g)
+	if len(s.bodies) != 1 {
+		t.Fatalf("service got %v requests, want 1", len(s.bodies))
+	}
+	if !strings.Contains(s.bodies[0], `"moves":[]`) {
+		t.Errorf("request %v, want the moves sent as []", s.bodies[0])
+	}
+}
+
+func TestHTTPPlayerFallsBackToARandomMove(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		reply func(w http.ResponseWriter, legal []string)
+	}{
+		{"server error", func(w http.ResponseWriter, legal []string) {
+			http.Error(w, "no move", http.StatusInternalServerError)
+		}},
+		{"illegal move", func(w http.ResponseWriter, legal []string) {
+			json.NewEncoder(w).Encode(map[string]string{"move": "{9,9}"})
+		}},
+		{"bad reply", func(w http.ResponseWriter, legal []string) {
+			io.WriteString(w, "not json")
+		}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(&moveService{reply: test.reply})
+			defer server.Close()
+
+			g := game.NewTicTacToe("Computer", "Computer", 0, 0, 1)
+			p := player.NewHTTPPlayer("Player 1", server.URL, 1, rand.New(rand.NewSource(1)))
+			if m := p.GetTurn(g); !isLegal(g, m) {
+				t.Errorf("GetTurn() = %v, want a legal move", m)
+			}
+		})
+	}
+}
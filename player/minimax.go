@@ -6,112 +6,52 @@ import (
 )
 
 type MinimaxPlayer struct {
-	Name     string
-	MaxDepth int
+	Name       string
+	MaxDepth   int
+	Rand       *rand.Rand
+	Nodes      *int64
+	Evaluation *Evaluation
 }
 
 func (p MinimaxPlayer) GetName() string {
 	return p.Name
 }
 
+func (p MinimaxPlayer) NodesSearched() int64 {
+	return loadNodes(p.Nodes)
+}
+
 type moveVal struct {
 	move int
 	val  int
 }
 
+// GetTurn scores every move on a goroutine of its own. Each is searched with
+// the widest window, so the search prunes nothing that would change its
+// score and it comes out the same as a full minimax search.
 func (p MinimaxPlayer) GetTurn(g game.Game) game.Move {
 	moves := g.GetPossibleMoves()
 	ch := make(chan moveVal)
 	scores := make([]int, len(moves))
+	crash := new(relay)
+	k := kernelOf(g)
 	for i, move := range moves {
-		go p.getScore(g, i, move, ch, 0)
-	}
-	i := 0
-	for i < len(scores) {
-		moveVal := <-ch
-		i++
-		scores[moveVal.move] = moveVal.val
-	}
-	bestScore := MinInt
-	for _, score := range scores {
-		if score >= bestScore {
-			bestScore = score
-		}
-	}
-	var bestMoves []game.Move
-	for i, score := range scores {
-		if score == bestScore {
-			bestMoves = append(bestMoves, moves[i])
-		}
-	}
-	return bestMoves[rand.Intn(len(bestMoves))]
-}
-
-func (p MinimaxPlayer) getScore(g game.Game, i int, m game.Move, ch chan moveVal, depth int) {
-	if depth > p.MaxDepth {
-		ch <- moveVal{move: i, val: g.CurrentScore(p)}
-		return
-	}
-	newG := g.MakeMove(m)
-	over, winner := newG.GameOver()
-	if over {
-		if winner == p {
-			ch <- moveVal{move: i, val: MaxInt - depth}
-		} else if winner.GetName() == "DRAW" {
-			ch <- moveVal{move: i, val: 0}
-		} else {
-			ch <- moveVal{move: i, val: MinInt + depth}
-		}
-	} else {
-		player := newG.GetPlayerTurn()
-		if p == player {
-			ch <- moveVal{move: i, val: p.getMax(newG, depth+1)}
-		} else {
-			ch <- moveVal{move: i, val: p.getMin(newG, depth+1)}
-		}
-	}
-}
-
-func (p MinimaxPlayer) getMax(g game.Game, depth int) int {
-	moves := g.GetPossibleMoves()
-	ch := make(chan moveVal)
-	scores := make([]int, len(moves))
-	for i, move := range moves {
-		go p.getScore(g, i, move, ch, depth+len(moves))
+		go func(i int, move game.Move) {
+			defer crash.catch(func() { ch <- moveVal{move: i} })
+			s := &search{me: p, nodes: p.Nodes, maxDepth: p.MaxDepth, wide: true}
+			ch <- moveVal{move: i, val: k.score(s, move, 0, MinInt, MaxInt)}
+		}(i, move)
 	}
-	i := 0
-	for i < len(scores) {
+	for range scores {
 		moveVal := <-ch
-		i++
 		scores[moveVal.move] = moveVal.val
 	}
-	bestScore := MinInt
-	for _, score := range scores {
-		if score >= bestScore {
-			bestScore = score
-		}
-	}
-	return bestScore
+	crash.rethrow()
+	p.Evaluation.record(bestScore(scores), true)
+	bestMoves := bestScoring(moves, scores)
+	return bestMoves[p.Rand.Intn(len(bestMoves))]
 }
 
-func (p MinimaxPlayer) getMin(g game.Game, depth int) int {
-	moves := g.GetPossibleMoves()
-	ch := make(chan moveVal)
-	scores := make([]int, len(moves))
-	for i, move := range moves {
-		go p.getScore(g, i, move, ch, depth+len(moves))
-	}
-	i := 0
-	for i < len(scores) {
-		moveVal := <-ch
-		i++
-		scores[moveVal.move] = moveVal.val
-	}
-	bestScore := MaxInt
-	for _, score := range scores {
-		if score <= bestScore {
-			bestScore = score
-		}
-	}
-	return bestScore
+func (p MinimaxPlayer) LastEval() (int, bool) {
+	return p.Evaluation.last()
 }
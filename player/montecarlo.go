@@ -8,12 +8,19 @@ import (
 type MonteCarloPlayer struct {
 	Name    string
 	MaxSims int
+	Rand    *rand.Rand
+	Nodes   *int64
+	Conduct *Conduct
 }
 
 func (p MonteCarloPlayer) GetName() string {
 	return p.Name
 }
 
+func (p MonteCarloPlayer) NodesSearched() int64 {
+	return loadNodes(p.Nodes)
+}
+
 func (p MonteCarloPlayer) GetTurn(g game.Game) game.Move {
 	moves := g.GetPossibleMoves()
 	if len(moves) == 1 {
@@ -21,15 +28,18 @@ func (p MonteCarloPlayer) GetTurn(g game.Game) game.Move {
 	}
 	wins := make([]int, len(moves))
 	attempts := make([]int, len(moves))
+	draws := make([]int, len(moves))
+	k := kernelOf(g)
 	for i := 0; i < p.MaxSims; i++ {
-		move := rand.Intn(len(moves))
+		move := p.Rand.Intn(len(moves))
 		attempts[move]++
-		newG := g.MakeMove(moves[move])
-		winner := p.runSimulation(newG)
+		countNode(p.Nodes)
+		winner, _, _ := k.playout(moves[move], p.Rand, nil, p.Nodes)
 		if winner == p {
 			wins[move] += 1
 		} else if winner.GetName() == "DRAW" {
 			wins[move] += 0
+			draws[move]++
 		} else {
 			wins[move] -= 1
 		}
@@ -57,17 +67,17 @@ func (p MonteCarloPlayer) GetTurn(g game.Game) game.Move {
 			bestMoves = append(bestMoves, moves[i])
 		}
 	}
-	return bestMoves[rand.Intn(len(bestMoves))]
+	rated := make([]float64, len(moves))
+	for i, w := range wins {
+		rated[i] = float64(w)
+	}
+	return p.Conduct.judgePlayouts(bestMoves[p.Rand.Intn(len(bestMoves))], rated, attempts, draws)
 }
 
-func (p MonteCarloPlayer) runSimulation(g game.Game) game.Player {
-	over, winner := g.GameOver()
-	if over {
-		return winner
-	} else {
-		moves := g.GetPossibleMoves()
-		move := moves[rand.Intn(len(moves))]
-		newG := g.MakeMove(move)
-		return p.runSimulation(newG)
-	}
+func (p MonteCarloPlayer) Negotiate(resign, draws bool) {
+	p.Conduct.negotiate(resign, draws)
+}
+
+func (p MonteCarloPlayer) AcceptDraw(g game.Game) bool {
+	return p.Conduct.acceptDraw()
 }
@@ -4,82 +4,202 @@ import (
 	"fmt"
 	"github.com/damargulis/game/interfaces"
 	"math/rand"
+	"time"
 )
 
 type MonteCarloTimePlayer struct {
 	Name    string
 	MaxTime int
+	// MaxNodes, when set, gives each move that many playout moves rather
+	// than MaxTime.
+	MaxNodes  int
+	Rand      *rand.Rand
+	Nodes     *int64
+	Pondering *Pondering
+	Conduct   *Conduct
 }
 
 func (p MonteCarloTimePlayer) GetName() string {
 	return p.Name
 }
 
+func (p MonteCarloTimePlayer) NodesSearched() int64 {
+	return loadNodes(p.Nodes)
+}
+
 func (p MonteCarloTimePlayer) GetTurn(g game.Game) game.Move {
+	return p.think(g, time.Duration(p.MaxTime)*time.Second)
+}
+
+// GetTimedTurn thinks for as long as the time manager gives the move.
+func (p MonteCarloTimePlayer) GetTimedTurn(g game.Game, c game.Clock) game.Move {
+	return p.think(g, moveTime(g, c))
+}
+
+func (p MonteCarloTimePlayer) think(g game.Game, limit time.Duration) game.Move {
 	moves := g.GetPossibleMoves()
 	if len(moves) == 1 {
 		return moves[0]
 	}
-	wins := make([]float64, len(moves))
-	attempts := make([]int, len(moves))
+	if p.MaxNodes > 0 {
+		s := playoutNodes(g, p, p.Nodes, moves, p.Rand, newBudget(p.MaxNodes))
+		bestMoves := bestRated(moves, s.wins, s.attempts)
+		return p.Conduct.judgePlayouts(bestMoves[p.Rand.Intn(len(bestMoves))], s.wins, s.attempts, s.draws)
+	}
+	s := newPlayoutStats(len(moves))
+	if t, ok := p.Pondering.take(g).(*playoutStats); ok && len(t.wins) == len(moves) {
+		s = t
+	}
+	wins, attempts, draws := s.wins, s.attempts, s.draws
 
-	timer := make(chan int)
+	timer := make(chan int, 1)
 	result := make(chan float64)
+	simRand := childRand(p.Rand)
+	crash := new(relay)
+	k := kernelOf(g)
 
-	move := rand.Intn(len(moves))
+	move := p.Rand.Intn(len(moves))
 	attempts[move]++
-	newG := g.MakeMove(moves[move])
-	go sleep(float64(p.MaxTime)*1000, timer)
-	go p.runSimulation(newG, result, 0)
+	countNode(p.Nodes)
+	deadline := time.Now().Add(limit)
+	go sleep(limit, timer)
+	go p.runSimulation(k, moves[move], simRand, result, crash)
 	iters := 0
+search:
 	for {
 		select {
 		case r := <-result:
+			crash.rethrow()
 			iters++
 			wins[move] += r
-			attempts[move]++
-			move = rand.Intn(len(moves))
-			newG = g.MakeMove(moves[move])
-			go p.runSimulation(newG, result, 0)
-		case <-timer:
-			fmt.Printf("Number iterations: %v\n", iters)
-			bestScore := float64(MinInt)
-			scores := make([]float64, len(moves))
-			for i := range moves {
-				if attempts[i] > 0 {
-					scores[i] = wins[i] / float64(attempts[i])
-				} else {
-					scores[i] = 0
-				}
-				if scores[i] >= bestScore {
-					bestScore = scores[i]
-				}
+			if r == 0 {
+				draws[move]++
 			}
-			var bestMoves []game.Move
-			for i, score := range scores {
-				if score == bestScore {
-					bestMoves = append(bestMoves, moves[i])
-				}
+			attempts[move]++
+			if past(deadline) {
+				break search
 			}
-			return bestMoves[rand.Intn(len(bestMoves))]
+			move = p.Rand.Intn(len(moves))
+			countNode(p.Nodes)
+			go p.runSimulation(k, moves[move], simRand, result, crash)
+		case <-timer:
+			break search
 		}
 	}
+	fmt.Printf("Number iterations: %v\n", iters)
+	bestMoves := bestRated(moves, wins, attempts)
+	return p.Conduct.judgePlayouts(bestMoves[p.Rand.Intn(len(bestMoves))], wins, attempts, draws)
+}
+
+func (p MonteCarloTimePlayer) runSimulation(k Kernel, m game.Move, r *rand.Rand, result chan float64, crash *relay) {
+	defer crash.catch(func() { result <- 0 })
+	score, _ := simulate(k, m, p, r, nil, p.Nodes)
+	result <- score
 }
 
-func (p MonteCarloTimePlayer) runSimulation(g game.Game, result chan float64, depth int) {
-	over, winner := g.GameOver()
-	if over {
-		if winner == p {
-			result <- float64(MaxInt-depth) / float64(MaxInt)
-		} else if winner.GetName() == "DRAW" {
-			result <- 0
-		} else {
-			result <- float64(MinInt+depth) / float64(MaxInt)
+type playoutStats struct {
+	wins     []float64
+	attempts []int
+	draws    []int
+}
+
+func newPlayoutStats(moves int) *playoutStats {
+	return &playoutStats{wins: make([]float64, moves), attempts: make([]int, moves), draws: make([]int, moves)}
+}
+
+// playoutNodes plays out random moves for me from g just as the timed
+// searches do, but one at a time until b runs out, discarding the playout
+// it ran out in, so that a node budget picks the same move on any machine.
+func playoutNodes(g game.Game, me game.Player, nodes *int64, moves []game.Move, r *rand.Rand, b *budget) *playoutStats {
+	s := newPlayoutStats(len(moves))
+	playouts(g, me, nodes, moves, s, r, childRand(r), b)
+	return s
+}
+
+// playouts adds playouts for me from g to s until b runs out, choosing the
+// first move with pick and the rest with sim.
+func playouts(g game.Game, me game.Player, nodes *int64, moves []game.Move, s *playoutStats, pick, sim *rand.Rand, b *budget) {
+	k := kernelOf(g)
+	for {
+		move := pick.Intn(len(moves))
+		if !b.spend() {
+			return
+		}
+		countNode(nodes)
+		score, ok := simulate(k, moves[move], me, sim, b, nodes)
+		if !ok {
+			return
 		}
-	} else {
-		moves := g.GetPossibleMoves()
-		move := moves[rand.Intn(len(moves))]
-		newG := g.MakeMove(move)
-		p.runSimulation(newG, result, depth+1)
+		s.wins[move] += score
+		s.attempts[move]++
+		if score == 0 {
+			s.draws[move]++
+		}
+	}
+}
+
+// predictionPlayouts is how many playouts pondering spends on each of the
+// opponent's replies to guess which they will play.
+const predictionPlayouts = 8
+
+// StartPondering guesses the opponent's reply and plays out the position
+// it leads to until they move.
+func (p MonteCarloTimePlayer) StartPondering(g game.Game) {
+	if p.Pondering == nil || p.MaxNodes > 0 {
+		return
+	}
+	r := childRand(p.Rand)
+	p.Pondering.start(func(b *budget) (game.Game, interface{}) {
+		pos := p.predictReply(g, r, b)
+		if pos == nil {
+			return nil, nil
+		}
+		moves := pos.GetPossibleMoves()
+		s := newPlayoutStats(len(moves))
+		playouts(pos, p, p.Nodes, moves, s, r, r, b)
+		return pos, s
+	})
+}
+
+func (p MonteCarloTimePlayer) OpponentMoved(g game.Game, m game.Move) {
+	p.Pondering.opponentMoved(g)
+}
+
+func (p MonteCarloTimePlayer) StopPondering() {
+	p.Pondering.halt()
+}
+
+func (p MonteCarloTimePlayer) Negotiate(resign, draws bool) {
+	p.Conduct.negotiate(resign, draws)
+}
+
+func (p MonteCarloTimePlayer) AcceptDraw(g game.Game) bool {
+	return p.Conduct.acceptDraw()
+}
+
+// predictReply takes the opponent's likely move in g to be the one whose
+// playouts go worst for p, returning the position it leads to if p is to
+// move there.
+func (p MonteCarloTimePlayer) predictReply(g game.Game, r *rand.Rand, b *budget) game.Game {
+	replies := g.GetPossibleMoves()
+	k := kernelOf(g)
+	best, bestScore := 0, float64(MaxInt)
+	for i, m := range replies {
+		total := 0.0
+		for j := 0; j < predictionPlayouts; j++ {
+			score, ok := simulate(k, m, p, r, b, p.Nodes)
+			if !ok {
+				return nil
+			}
+			total += score
+		}
+		if total < bestScore {
+			best, bestScore = i, total
+		}
+	}
+	pos := g.MakeMove(replies[best])
+	if over, _ := pos.GameOver(); over || pos.GetPlayerTurn() != p {
+		return nil
 	}
+	return pos
 }
@@ -0,0 +1,49 @@
+package player_test
+
+import (
+	"github.com/damargulis/game/game"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+// busy keeps every CPU spinning until stop is closed.
+func busy(stop chan bool) *sync.WaitGroup {
+	var wg sync.WaitGroup
+	for i := 0; i < runtime.NumCPU(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+			}
+		}()
+	}
+	return &wg
+}
+
+func TestNodeBudgetsPlayTheSameGameEveryRun(t *testing.T) {
+	for _, engine := range []string{"AlphabetaNodes", "MontecarloNodes", "ComboNodes"} {
+		t.Run(engine, func(t *testing.T) {
+			play := func() game.Result {
+				res := game.Play(game.NewConnect4(engine, engine, 400, 300, 3), false)
+				res.Duration, res.MoveTime = 0, [2]time.Duration{}
+				return res
+			}
+			want := play()
+			stop := make(chan bool)
+			wg := busy(stop)
+			got := play()
+			close(stop)
+			wg.Wait()
+			if got != want {
+				t.Errorf("second game under load %+v, want the first %+v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package player
+
+import (
+	"github.com/damargulis/game/interfaces"
+	"sync/atomic"
+)
+
+// Pondering runs a player's search on the opponent's time. The search
+// guesses the opponent's reply and works on the position it leads to; if
+// that reply is played the work is handed to the player's next turn.
+type Pondering struct {
+	Hits   int
+	Misses int
+
+	stop      atomic.Bool
+	done      chan struct{}
+	predicted game.Game
+	work      interface{}
+	crash     relay
+}
+
+// start runs search in the background until halted. search returns the
+// position it predicted and whatever it found there.
+func (s *Pondering) start(search func(b *budget) (game.Game, interface{})) {
+	s.halt()
+	s.predicted, s.work = nil, nil
+	s.stop.Store(false)
+	s.done = make(chan struct{})
+	go func() {
+		defer close(s.done)
+		defer s.crash.catch(func() {})
+		s.predicted, s.work = search(stoppable(&s.stop))
+	}()
+}
+
+func (s *Pondering) halt() {
+	if s == nil || s.done == nil {
+		return
+	}
+	s.stop.Store(true)
+	<-s.done
+	s.done = nil
+}
+
+// opponentMoved stops the search and keeps its work only if the opponent
+// played into the predicted position.
+func (s *Pondering) opponentMoved(g game.Game) {
+	if s == nil || s.done == nil {
+		return
+	}
+	s.halt()
+	s.crash.rethrow()
+	if s.predicted != nil && samePosition(s.predicted, g) {
+		s.Hits++
+	} else {
+		s.Misses++
+		s.predicted, s.work = nil, nil
+	}
+}
+
+// take hands over the work pondered for g, if there is any.
+func (s *Pondering) take(g game.Game) interface{} {
+	if s == nil {
+		return nil
+	}
+	s.halt()
+	s.crash.rethrow()
+	work := s.work
+	if work == nil || !samePosition(s.predicted, g) {
+		return nil
+	}
+	s.predicted, s.work = nil, nil
+	return work
+}
+
+func samePosition(a, b game.Game) bool {
+	return a.GetRound() == b.GetRound() &&
+		a.GetPlayerTurn().GetName() == b.GetPlayerTurn().GetName() &&
+		a.BoardString() == b.BoardString()
+}
@@ -0,0 +1,85 @@
+package player_test
+
+import (
+	"github.com/damargulis/game/game"
+	interfaces "github.com/damargulis/game/interfaces"
+	"github.com/damargulis/game/player"
+	"testing"
+	"time"
+)
+
+// ponderer is a player that ponders, with the Pondering it keeps its work
+// in.
+type ponderer interface {
+	interfaces.TimedPlayer
+	interfaces.Ponderer
+	pondering() *player.Pondering
+}
+
+type alphabetaPonderer struct{ player.AlphabetaTimePlayer }
+
+func (p alphabetaPonderer) pondering() *player.Pondering { return p.Pondering }
+
+type montecarloPonderer struct{ player.MonteCarloTimePlayer }
+
+func (p montecarloPonderer) pondering() *player.Pondering { return p.Pondering }
+
+// ponder has p ponder on pos for a while, returning the reply it predicted
+// and another the opponent could play instead.
+func ponder(t *testing.T, p ponderer, pos interfaces.Game) (hit, miss interfaces.Move) {
+	t.Helper()
+	p.StartPondering(pos)
+	time.Sleep(20 * time.Millisecond)
+	predicted := player.Predicted(p.pondering())
+	if predicted == nil {
+		t.Fatal("pondering predicted no reply")
+	}
+	for _, m := range pos.GetPossibleMoves() {
+		if pos.MakeMove(m).BoardString() == predicted.BoardString() {
+			hit = m
+		} else {
+			miss = m
+		}
+	}
+	if hit == nil {
+		t.Fatalf("pondering predicted\n%v\nwhich no reply leads to", predicted.BoardString())
+	}
+	return hit, miss
+}
+
+func TestPonderingHitsKeepTheSearch(t *testing.T) {
+	for _, engine := range []string{"AlphabetaTime", "MontecarloTime"} {
+		t.Run(engine, func(t *testing.T) {
+			g := game.NewConnect4(engine, engine, 10, 10, 1)
+			var p ponderer
+			switch q := g.GetPlayerTurn().(type) {
+			case player.AlphabetaTimePlayer:
+				p = alphabetaPonderer{q}
+			case player.MonteCarloTimePlayer:
+				p = montecarloPonderer{q}
+			}
+			pos := g.MakeMove(g.GetPossibleMoves()[0])
+
+			_, miss := ponder(t, p, pos)
+			p.OpponentMoved(pos.MakeMove(miss), miss)
+			if s := p.pondering(); s.Hits != 0 || s.Misses != 1 || player.Pondered(s) != 0 {
+				t.Errorf("after a miss: %v hits, %v misses, %v work kept", s.Hits, s.Misses, player.Pondered(s))
+			}
+
+			hit, _ := ponder(t, p, pos)
+			next := pos.MakeMove(hit)
+			p.OpponentMoved(next, hit)
+			s := p.pondering()
+			if s.Hits != 1 || s.Misses != 1 {
+				t.Errorf("after a hit: %v hits, %v misses", s.Hits, s.Misses)
+			}
+			if player.Pondered(s) == 0 {
+				t.Fatal("a hit kept no work")
+			}
+			p.GetTimedTurn(next, interfaces.Clock{PerMove: 4 * time.Millisecond})
+			if n := player.Pondered(s); n != 0 {
+				t.Errorf("the next turn left %v work behind", n)
+			}
+		})
+	}
+}
@@ -0,0 +1,242 @@
+package player
+
+import (
+	"github.com/damargulis/game/interfaces"
+	"math/rand"
+)
+
+// Kernel runs the searches from one position. Searches are written against
+// game.Mutable, so a game whose moves and positions have their own types
+// searches them without boxing: it is Typed and makes its Kernel with
+// NewKernel, or with NewMutableKernel if it can also play moves in place.
+// Any other game is searched as a game.Boxed.
+type Kernel interface {
+	// score is m's alphabeta score under s.
+	score(s *search, m game.Move, depth, alpha, beta int) int
+	// playout plays m and then random moves to the end of the game,
+	// returning the winner and the random moves it took, or false if b ran
+	// out first.
+	playout(m game.Move, r *rand.Rand, b *budget, nodes *int64) (game.Player, int, bool)
+}
+
+// Typed is a game with a Kernel of its own.
+type Typed interface {
+	Kernel() Kernel
+}
+
+func NewKernel[P game.Position[P, M], M any](g P) Kernel {
+	return kernel[P, M]{g}
+}
+
+func kernelOf(g game.Game) Kernel {
+	if t, ok := g.(Typed); ok {
+		return t.Kernel()
+	}
+	return NewKernel[game.Boxed, game.Move](game.Boxed{Game: g})
+}
+
+// NewMutableKernel is a Kernel for a game whose pointer is Mutable. Each
+// search copies the position once and plays its moves on the copy.
+func NewMutableKernel[P any, Q interface {
+	*P
+	game.Mutable[M, U]
+}, M any, U any](g P) Kernel {
+	return mutableKernel[P, Q, M, U]{g}
+}
+
+type kernel[P game.Position[P, M], M any] struct {
+	g P
+}
+
+func (k kernel[P, M]) score(s *search, m game.Move, depth, alpha, beta int) int {
+	return alphabeta[*copying[P, M], M, P](s, &copying[P, M]{pos: k.g}, m.(M), depth, alpha, beta)
+}
+
+func (k kernel[P, M]) playout(m game.Move, r *rand.Rand, b *budget, nodes *int64) (game.Player, int, bool) {
+	return playout[*copying[P, M], M, P](&copying[P, M]{pos: k.g.Play(m.(M))}, r, b, nodes)
+}
+
+type mutableKernel[P any, Q interface {
+	*P
+	game.Mutable[M, U]
+}, M any, U any] struct {
+	g P
+}
+
+func (k mutableKernel[P, Q, M, U]) score(s *search, m game.Move, depth, alpha, beta int) int {
+	pos := k.g
+	return alphabeta[Q, M, U](s, Q(&pos), m.(M), depth, alpha, beta)
+}
+
+func (k mutableKernel[P, Q, M, U]) playout(m game.Move, r *rand.Rand, b *budget, nodes *int64) (game.Player, int, bool) {
+	pos := k.g
+	Q(&pos).Apply(m.(M))
+	return playout[Q, M, U](Q(&pos), r, b, nodes)
+}
+
+// copying is a Position as a Mutable, undoing a move by going back to the
+// position before it.
+type copying[P game.Position[P, M], M any] struct {
+	pos P
+	buf []M
+}
+
+func (c *copying[P, M]) RandomMove(r *rand.Rand) M {
+	return randomMove[M](&c.pos, &c.buf, r)
+}
+
+func (c *copying[P, M]) GetPlayerTurn() game.Player {
+	return c.pos.GetPlayerTurn()
+}
+
+func (c *copying[P, M]) PossibleMoves() []M {
+	return c.pos.PossibleMoves()
+}
+
+func (c *copying[P, M]) Apply(m M) P {
+	before := c.pos
+	c.pos = c.pos.Play(m)
+	return before
+}
+
+func (c *copying[P, M]) Undo(before P) {
+	c.pos = before
+}
+
+func (c *copying[P, M]) GameOver() (bool, game.Player) {
+	return c.pos.GameOver()
+}
+
+func (c *copying[P, M]) CurrentScore(p game.Player) int {
+	return c.pos.CurrentScore(p)
+}
+
+// search is an alphabeta search for me to maxDepth. It is wide for
+// AlphabetaPlayer, whose depth grows by the number of moves in each
+// position and which scores nearer wins higher, and grows by a ply for the
+// iterative deepening players. cut records that it scored a position at
+// maxDepth by its heuristic or ran out of budget, so its score isn't
+// proven.
+type search struct {
+	me       game.Player
+	nodes    *int64
+	maxDepth int
+	wide     bool
+	budget   *budget
+	cut      bool
+}
+
+// scored is a move's score and whether its search proved it.
+type scored struct {
+	score  int
+	proven bool
+}
+
+func alphabeta[G game.Mutable[M, U], M any, U any](s *search, g G, m M, depth, alpha, beta int) int {
+	if depth > s.maxDepth {
+		s.cut = true
+		return g.CurrentScore(s.me)
+	}
+	if !s.budget.spend() {
+		s.cut = true
+		return 0
+	}
+	countNode(s.nodes)
+	defer g.Undo(g.Apply(m))
+	over, winner := g.GameOver()
+	if over {
+		mate := 0
+		if s.wide {
+			mate = depth
+		}
+		if winner == s.me {
+			return MaxInt - mate
+		} else if winner.GetName() == "DRAW" {
+			return 0
+		} else {
+			return MinInt + mate
+		}
+	}
+	moves := g.PossibleMoves()
+	next := depth + 1
+	if s.wide {
+		next += len(moves)
+	}
+	if g.GetPlayerTurn() == s.me {
+		v := MinInt
+		for _, move := range moves {
+			score := alphabeta[G, M, U](s, g, move, next, alpha, beta)
+			if score > v {
+				v = score
+			}
+			if v > alpha {
+				alpha = v
+			}
+			if beta <= alpha {
+				break
+			}
+		}
+		return v
+	}
+	v := MaxInt
+	for _, move := range moves {
+		score := alphabeta[G, M, U](s, g, move, next, alpha, beta)
+		if score < v {
+			v = score
+		}
+		if beta < v {
+			beta = v
+		}
+		if beta <= alpha {
+			break
+		}
+	}
+	return v
+}
+
+func playout[G game.Mutable[M, U], M any, U any](g G, r *rand.Rand, b *budget, nodes *int64) (game.Player, int, bool) {
+	// Room for the moves of any position in these games, so that listing
+	// them never grows buf.
+	buf := make([]M, 0, 64)
+	for depth := 0; ; depth++ {
+		if over, winner := g.GameOver(); over {
+			return winner, depth, true
+		}
+		if !b.spend() {
+			return nil, depth, false
+		}
+		move := randomMove[M](g, &buf, r)
+		countNode(nodes)
+		g.Apply(move)
+	}
+}
+
+// randomMove picks a random legal move in g, by g's own RandomMove if it has
+// one, or else from g's moves listed into buf.
+func randomMove[M any](g any, buf *[]M, r *rand.Rand) M {
+	if rm, ok := g.(game.RandomMover[M]); ok {
+		return rm.RandomMove(r)
+	}
+	if a, ok := g.(game.MoveAppender[M]); ok {
+		*buf = a.AppendMoves((*buf)[:0])
+	} else {
+		*buf = g.(interface{ PossibleMoves() []M }).PossibleMoves()
+	}
+	return (*buf)[r.Intn(len(*buf))]
+}
+
+// simulate plays m and then plays the game out at random, scoring the
+// result for me, or reporting false if b ran out first.
+func simulate(k Kernel, m game.Move, me game.Player, r *rand.Rand, b *budget, nodes *int64) (float64, bool) {
+	winner, depth, ok := k.playout(m, r, b, nodes)
+	if !ok {
+		return 0, false
+	}
+	if winner == me {
+		return float64(MaxInt-depth) / float64(MaxInt), true
+	} else if winner.GetName() == "DRAW" {
+		return 0, true
+	} else {
+		return float64(MinInt+depth) / float64(MaxInt), true
+	}
+}
@@ -0,0 +1,91 @@
+package player_test
+
+import (
+	"github.com/damargulis/game/game"
+	interfaces "github.com/damargulis/game/interfaces"
+	"github.com/damargulis/game/player"
+	"math/rand"
+	"runtime"
+	"testing"
+)
+
+var kernelGames = []struct {
+	name  string
+	g     interfaces.Game
+	depth int
+}{
+	{"tictactoe", game.NewTicTacToe("Computer", "Computer", 0, 0, 1), 4},
+	{"connect4", game.NewConnect4("Computer", "Computer", 0, 0, 1), 3},
+	{"reversi", game.NewReversi("Computer", "Computer", 0, 0, 1), 2},
+}
+
+// benchmarkKernel runs search on k b.N times, reporting the nodes it
+// searched and the allocations it made for each.
+func benchmarkKernel(b *testing.B, search func(nodes *int64)) {
+	var nodes int64
+	var before, after runtime.MemStats
+	b.ReportAllocs()
+	runtime.ReadMemStats(&before)
+	for i := 0; i < b.N; i++ {
+		search(&nodes)
+	}
+	runtime.ReadMemStats(&after)
+	b.ReportMetric(float64(nodes)/float64(b.N), "nodes/op")
+	if nodes > 0 {
+		b.ReportMetric(float64(after.Mallocs-before.Mallocs)/float64(nodes), "allocs/node")
+	}
+}
+
+// BenchmarkKernels compares each game's own kernel with the boxed one,
+// which goes through the Game interface, for an alphabeta search of every
+// first move and for random playouts.
+func BenchmarkKernels(b *testing.B) {
+	for _, kg := range kernelGames {
+		g := kg.g
+		me := g.GetPlayerTurn()
+		moves := g.GetPossibleMoves()
+		kernels := []struct {
+			name string
+			k    player.Kernel
+		}{
+			{"typed", player.KernelOf(g)},
+			{"boxed", player.BoxedKernel(g)},
+		}
+		for _, k := range kernels {
+			b.Run(kg.name+"/alphabeta/"+k.name, func(b *testing.B) {
+				benchmarkKernel(b, func(nodes *int64) {
+					for _, m := range moves {
+						player.Score(k.k, me, m, kg.depth, nodes)
+					}
+				})
+			})
+			b.Run(kg.name+"/playout/"+k.name, func(b *testing.B) {
+				r := rand.New(rand.NewSource(1))
+				benchmarkKernel(b, func(nodes *int64) {
+					player.Playout(k.k, moves[r.Intn(len(moves))], r, nodes)
+				})
+			})
+		}
+	}
+}
+
+// TestPlayoutsBarelyAllocate checks that a game's own kernel plays out
+// without allocating on every ply, as boxing each position or move would.
+func TestPlayoutsBarelyAllocate(t *testing.T) {
+	for _, kg := range kernelGames {
+		k := player.KernelOf(kg.g)
+		moves := kg.g.GetPossibleMoves()
+		r := rand.New(rand.NewSource(1))
+		var nodes int64
+		allocs := testing.AllocsPerRun(100, func() {
+			player.Playout(k, moves[r.Intn(len(moves))], r, &nodes)
+		})
+		// AllocsPerRun plays one more playout than it counts, to warm up.
+		plies := float64(nodes) / 101
+		if perPly := allocs / plies; perPly > 0.5 {
+			t.Errorf("%v: %.2f allocations per playout ply, want next to none", kg.name, perPly)
+		} else {
+			t.Logf("%v: %.3f allocations per playout ply", kg.name, perPly)
+		}
+	}
+}
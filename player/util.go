@@ -1,10 +1,181 @@
 package player
 
 import (
+	"fmt"
+	"github.com/damargulis/game/interfaces"
+	"math/rand"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
-func sleep(t float64, ch chan int) {
-	time.Sleep(time.Duration(t) * time.Millisecond)
+func sleep(t time.Duration, ch chan int) {
+	time.Sleep(t)
 	ch <- 0
 }
+
+// past reports whether deadline has gone by. The timer sleep sets can lag
+// well behind a busy search on a loaded machine, so the timed searches
+// check this between results as well.
+func past(deadline time.Time) bool {
+	return time.Now().After(deadline)
+}
+
+// Panic is a recovered panic and the stack it was raised on, so it can be
+// raised again on another goroutine without losing where it came from.
+type Panic struct {
+	Value interface{}
+	Stack string
+}
+
+func (p Panic) String() string {
+	return fmt.Sprint(p.Value)
+}
+
+// Recovered wraps a value from recover, which must be called in the same
+// deferred function, with the stack it was raised on.
+func Recovered(v interface{}) Panic {
+	if p, ok := v.(Panic); ok {
+		return p
+	}
+	return Panic{Value: v, Stack: string(debug.Stack())}
+}
+
+// relay carries the first panic on a search's goroutines back to the
+// goroutine waiting for the search, so Play can recover it there.
+type relay struct {
+	mu    sync.Mutex
+	panic *Panic
+}
+
+// catch, deferred on a search goroutine, recovers a panic and calls reply so
+// whoever waits on the goroutine still hears back.
+func (r *relay) catch(reply func()) {
+	v := recover()
+	if v == nil {
+		return
+	}
+	p := Recovered(v)
+	r.mu.Lock()
+	if r.panic == nil {
+		r.panic = &p
+	}
+	r.mu.Unlock()
+	reply()
+}
+
+// rethrow raises the panic caught on the search's goroutines, if there was
+// one.
+func (r *relay) rethrow() {
+	r.mu.Lock()
+	p := r.panic
+	r.mu.Unlock()
+	if p != nil {
+		panic(*p)
+	}
+}
+
+func childRand(r *rand.Rand) *rand.Rand {
+	return rand.New(rand.NewSource(r.Int63()))
+}
+
+func countNode(nodes *int64) {
+	if nodes != nil {
+		atomic.AddInt64(nodes, 1)
+	}
+}
+
+func loadNodes(nodes *int64) int64 {
+	if nodes == nil {
+		return 0
+	}
+	return atomic.LoadInt64(nodes)
+}
+
+// budget is the number of nodes a search has left, and whether it has been
+// told to stop. A nil budget never runs out, for searches bounded by time
+// instead.
+type budget struct {
+	left    int
+	limited bool
+	stop    *atomic.Bool
+}
+
+func newBudget(nodes int) *budget {
+	if nodes <= 0 {
+		return nil
+	}
+	return &budget{left: nodes, limited: true}
+}
+
+// stoppable is a budget that lasts until stop is set.
+func stoppable(stop *atomic.Bool) *budget {
+	return &budget{stop: stop}
+}
+
+func (b *budget) spend() bool {
+	if b.empty() {
+		return false
+	}
+	if b != nil && b.limited {
+		b.left--
+	}
+	return true
+}
+
+func (b *budget) empty() bool {
+	if b == nil {
+		return false
+	}
+	return (b.limited && b.left <= 0) || (b.stop != nil && b.stop.Load())
+}
+
+func bestScore(scores []int) int {
+	best := MinInt
+	for _, score := range scores {
+		if score > best {
+			best = score
+		}
+	}
+	return best
+}
+
+func bestScoring(moves []game.Move, scores []int) []game.Move {
+	bestScore := bestScore(scores)
+	var bestMoves []game.Move
+	for i, score := range scores {
+		if score == bestScore {
+			bestMoves = append(bestMoves, moves[i])
+		}
+	}
+	return bestMoves
+}
+
+func bestRated(moves []game.Move, wins []float64, attempts []int) []game.Move {
+	bestScore := bestRate(wins, attempts)
+	var bestMoves []game.Move
+	for i := range moves {
+		if rate(wins[i], attempts[i]) == bestScore {
+			bestMoves = append(bestMoves, moves[i])
+		}
+	}
+	return bestMoves
+}
+
+func bestRate(wins []float64, attempts []int) float64 {
+	bestScore := float64(MinInt)
+	for i := range wins {
+		if r := rate(wins[i], attempts[i]); r >= bestScore {
+			bestScore = r
+		}
+	}
+	return bestScore
+}
+
+func rate(wins float64, attempts int) float64 {
+	if attempts > 0 {
+		return wins / float64(attempts)
+	}
+	return 0
+}
@@ -0,0 +1,227 @@
+// Package protocol serves our engines to other programs over stdin and
+// stdout, speaking the protocol player.ExternalPlayer expects along with a
+// few commands for GUIs and arena tools.
+package protocol
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/damargulis/game/game"
+	interfaces "github.com/damargulis/game/interfaces"
+	"github.com/damargulis/game/player"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Server answers one command per line:
+//
+//	engine <type> <param>     play on as another engine, as in game.PlayerTypes
+//	newgame <game> [<seat>]   start a game from game.Games; seat is ignored
+//	position [<move> ...]     the moves played since the start of the game
+//	play <move>               play one more move
+//	legal [<move> ...]        ignored; the legal moves are worked out here
+//	time <ms> [<inc ms>]      the engine's clock and increment for later moves
+//	go [<ms>]                 reply bestmove for the side to move
+//	genmove [<ms>]            go, and play the move
+//	board                     print the board, then a line "."
+//	isready                   reply readyok
+//	quit                      stop serving
+//
+// Moves are written as player.MoveText writes them. go and genmove think for
+// ms when given it, or else by the clock when there is one. A command that
+// can't be carried out is answered with "error <why>".
+type Server struct {
+	Type  string
+	Param int
+	Seed  int64
+
+	name  string
+	start interfaces.Game
+	g     interfaces.Game
+	moves []string
+	clock interfaces.Clock
+	out   *bufio.Writer
+}
+
+// Serve reads commands from r and writes replies to w until quit or the end
+// of r.
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	s.out = bufio.NewWriter(w)
+	in := bufio.NewScanner(r)
+	for in.Scan() {
+		fields := strings.Fields(in.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if fields[0] == "quit" {
+			break
+		}
+		if err := s.command(fields[0], fields[1:]); err != nil {
+			fmt.Fprintln(s.out, "error", err)
+		}
+		if err := s.out.Flush(); err != nil {
+			return err
+		}
+	}
+	return in.Err()
+}
+
+func (s *Server) command(name string, args []string) (err error) {
+	// A panicking engine or game fails the command, not the server.
+	defer func() {
+		if v := recover(); v != nil {
+			err = fmt.Errorf("%v", v)
+		}
+	}()
+	switch name {
+	case "engine":
+		if len(args) != 2 {
+			return fmt.Errorf("engine wants a type and a parameter")
+		}
+		param, err := strconv.Atoi(args[1])
+		if err != nil {
+			return err
+		}
+		if !game.IsPlayerType(args[0]) || args[0] == "Human" {
+			return fmt.Errorf("engine %v not recognized", args[0])
+		}
+		s.Type, s.Param = args[0], param
+		if s.name != "" {
+			if err := s.setup(); err != nil {
+				return err
+			}
+			return s.position(s.moves)
+		}
+	case "newgame":
+		if len(args) == 0 {
+			return fmt.Errorf("newgame wants a game")
+		}
+		if _, ok := game.Games[args[0]]; !ok {
+			return fmt.Errorf("game %v not recognized", args[0])
+		}
+		s.name = args[0]
+		if err := s.setup(); err != nil {
+			return err
+		}
+		return s.position(nil)
+	case "position":
+		return s.position(args)
+	case "play":
+		if len(args) != 1 {
+			return fmt.Errorf("play wants one move")
+		}
+		return s.play(args[0])
+	case "legal":
+	case "time":
+		return s.setClock(args)
+	case "go", "genmove":
+		if s.g == nil {
+			return fmt.Errorf("no game")
+		}
+		if over, _ := s.g.GameOver(); over {
+			return fmt.Errorf("the game is over")
+		}
+		var think time.Duration
+		if len(args) > 0 {
+			ms, err := strconv.Atoi(args[0])
+			if err != nil {
+				return err
+			}
+			think = time.Duration(ms) * time.Millisecond
+		}
+		m := player.MoveText(s.bestMove(think))
+		fmt.Fprintln(s.out, "bestmove", m)
+		if name == "genmove" {
+			return s.play(m)
+		}
+	case "board":
+		if s.g == nil {
+			return fmt.Errorf("no game")
+		}
+		fmt.Fprintln(s.out, s.g.BoardString())
+		fmt.Fprintln(s.out, ".")
+	case "isready":
+		fmt.Fprintln(s.out, "readyok")
+	default:
+		return fmt.Errorf("unknown command %v", name)
+	}
+	return nil
+}
+
+// setup builds the start of the game with a new engine, which is kept for
+// every position until the next newgame or engine.
+func (s *Server) setup() error {
+	g, err := game.New(s.name, s.Type, s.Type, s.Param, s.Param, s.Seed)
+	if err != nil {
+		return err
+	}
+	s.start = g
+	return nil
+}
+
+// position plays moves from the start of the game. The engine carries on
+// from the last position, so its random numbers, conduct and pondering are
+// not reset.
+func (s *Server) position(moves []string) error {
+	if s.start == nil {
+		return fmt.Errorf("no game")
+	}
+	s.g, s.moves = s.start, nil
+	for _, m := range moves {
+		if err := s.play(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) play(text string) error {
+	if s.g == nil {
+		return fmt.Errorf("no game")
+	}
+	for _, m := range s.g.GetPossibleMoves() {
+		if player.MoveText(m) == text {
+			s.g = s.g.MakeMove(m)
+			s.moves = append(s.moves, text)
+			return nil
+		}
+	}
+	return fmt.Errorf("%v is not a legal move", text)
+}
+
+func (s *Server) setClock(args []string) error {
+	if len(args) == 0 || len(args) > 2 {
+		return fmt.Errorf("time wants the time left and maybe an increment, in ms")
+	}
+	var ms [2]int
+	for i, a := range args {
+		v, err := strconv.Atoi(a)
+		if err != nil {
+			return err
+		}
+		ms[i] = v
+	}
+	s.clock = interfaces.Clock{
+		Remaining: time.Duration(ms[0]) * time.Millisecond,
+		Increment: time.Duration(ms[1]) * time.Millisecond,
+	}
+	return nil
+}
+
+// bestMove asks the engine to move, thinking for about think if it is set
+// and the engine manages its own time.
+func (s *Server) bestMove(think time.Duration) interfaces.Move {
+	p := s.g.GetPlayerTurn()
+	if t, ok := p.(interfaces.TimedPlayer); ok {
+		if think > 0 {
+			// A per move limit alone is budgeted at three quarters.
+			return t.GetTimedTurn(s.g, interfaces.Clock{PerMove: think * 4 / 3})
+		}
+		if s.clock.Remaining > 0 {
+			return t.GetTimedTurn(s.g, s.clock)
+		}
+	}
+	return p.GetTurn(s.g)
+}
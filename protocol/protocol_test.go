@@ -0,0 +1,61 @@
+package protocol_test
+
+import (
+	"github.com/damargulis/game/protocol"
+	"strings"
+	"testing"
+)
+
+// serve runs commands through a server playing the random Computer engine
+// and returns its replies, one per line.
+func serve(t *testing.T, commands ...string) []string {
+	t.Helper()
+	var out strings.Builder
+	s := protocol.Server{Type: "Computer", Seed: 7}
+	if err := s.Serve(strings.NewReader(strings.Join(commands, "\n")), &out); err != nil {
+		t.Fatal(err)
+	}
+	replies := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	for _, r := range replies {
+		if strings.HasPrefix(r, "error") {
+			t.Fatalf("%v after %q", r, commands)
+		}
+	}
+	return replies
+}
+
+func TestPositionKeepsTheEngine(t *testing.T) {
+	commands := []string{"newgame tictactoe"}
+	for i := 0; i < 8; i++ {
+		commands = append(commands, "position", "go")
+	}
+	replies := serve(t, commands...)
+	first := serve(t, "newgame tictactoe", "go")
+	if replies[0] != first[0] {
+		t.Errorf("first reply %v, a new server replies %v", replies[0], first[0])
+	}
+	for _, r := range replies[1:] {
+		if r != replies[0] {
+			return
+		}
+	}
+	t.Errorf("the random engine replied %v to every position; it was started again each time", replies[0])
+}
+
+func TestPositionReplaysTheGame(t *testing.T) {
+	played := serve(t, "newgame tictactoe", "genmove", "genmove", "board")
+	var moves []string
+	for _, r := range played[:2] {
+		moves = append(moves, strings.TrimPrefix(r, "bestmove "))
+	}
+	board := strings.Join(played[2:], "\n")
+	replayed := serve(t, "newgame tictactoe", "genmove", "genmove", "position "+strings.Join(moves, " "), "board")
+	if got := strings.Join(replayed[2:], "\n"); got != board {
+		t.Errorf("after position %v the board is\n%v\nwant\n%v", moves, got, board)
+	}
+	start := serve(t, "newgame tictactoe", "board")
+	cleared := serve(t, "newgame tictactoe", "genmove", "position", "board")
+	if got, want := strings.Join(cleared[1:], "\n"), strings.Join(start, "\n"); got != want {
+		t.Errorf("after an empty position the board is\n%v\nwant\n%v", got, want)
+	}
+}
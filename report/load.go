@@ -0,0 +1,194 @@
+package report
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/damargulis/game/experiment"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Load reads result files written by the experiment package, either as CSV
+// or JSON Lines, along with the older headerless depth sweep CSVs of
+// p1depth,p2depth,p1wins,p2wins,ties,time[,seed].
+func Load(fileNames []string) ([]experiment.Record, error) {
+	var records []experiment.Record
+	for _, fileName := range fileNames {
+		f, err := os.Open(fileName)
+		if err != nil {
+			return nil, err
+		}
+		var rs []experiment.Record
+		if strings.HasSuffix(fileName, ".jsonl") {
+			rs, err = readJSONL(f)
+		} else {
+			rs, err = readCSV(f, strings.TrimSuffix(filepath.Base(fileName), filepath.Ext(fileName)))
+		}
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v", fileName, err)
+		}
+		records = append(records, rs...)
+	}
+	return records, nil
+}
+
+func readJSONL(r io.Reader) ([]experiment.Record, error) {
+	var records []experiment.Record
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var record experiment.Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("line %v: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	return records, scanner.Err()
+}
+
+func readCSV(r io.Reader, name string) ([]experiment.Record, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	if rows[0][0] != "game" {
+		return readLegacy(rows, name)
+	}
+	columns := map[string]int{}
+	for i, c := range rows[0] {
+		columns[c] = i
+	}
+	var records []experiment.Record
+	for line, row := range rows[1:] {
+		p := rowParser{row: row, columns: columns}
+		record := experiment.Record{
+			Game:         p.str("game"),
+			Player1:      p.str("player1"),
+			Config1:      p.int("config1"),
+			Player2:      p.str("player2"),
+			Config2:      p.int("config2"),
+			Seed:         p.int64("seed"),
+			Games:        p.int("games"),
+			P1Wins:       p.int("p1_wins"),
+			P2Wins:       p.int("p2_wins"),
+			Draws:        p.int("draws"),
+			MeanMargin:   p.float("mean_margin"),
+			TotalMillis:  p.float("total_ms"),
+			Moves:        p.int("moves"),
+			MoveMillis:   p.float("ms_per_move"),
+			P1Nodes:      p.int64("p1_nodes"),
+			P2Nodes:      p.int64("p2_nodes"),
+			LLR:          p.float("llr"),
+			Elo:          p.float("elo"),
+			EloError:     p.float("elo_error"),
+			Decision:     p.str("decision"),
+			P1MoveMillis: p.float("p1_ms_per_move"),
+			P2MoveMillis: p.float("p2_ms_per_move"),
+			P1Timeouts:   p.int("p1_timeouts"),
+			P2Timeouts:   p.int("p2_timeouts"),
+		}
+		if record.Reasons, err = experiment.ParseReasons(p.str("reasons")); err != nil {
+			return nil, fmt.Errorf("line %v: %v", line+2, err)
+		}
+		if p.err != nil {
+			return nil, fmt.Errorf("line %v: %v", line+2, p.err)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+type rowParser struct {
+	row     []string
+	columns map[string]int
+	err     error
+}
+
+func (p *rowParser) str(column string) string {
+	i, ok := p.columns[column]
+	if !ok || i >= len(p.row) {
+		return ""
+	}
+	return p.row[i]
+}
+
+func (p *rowParser) int(column string) int {
+	return int(p.float(column))
+}
+
+func (p *rowParser) int64(column string) int64 {
+	s := p.str(column)
+	if s == "" {
+		return 0
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil && p.err == nil {
+		p.err = fmt.Errorf("column %v: %v", column, err)
+	}
+	return n
+}
+
+func (p *rowParser) float(column string) float64 {
+	s := p.str(column)
+	if s == "" {
+		return 0
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil && p.err == nil {
+		p.err = fmt.Errorf("column %v: %v", column, err)
+	}
+	return f
+}
+
+func readLegacy(rows [][]string, name string) ([]experiment.Record, error) {
+	var records []experiment.Record
+	for line, row := range rows {
+		if len(row) < 6 {
+			return nil, fmt.Errorf("line %v: want at least 6 fields, got %v", line+1, len(row))
+		}
+		var ints [5]int
+		for i := range ints {
+			n, err := strconv.Atoi(row[i])
+			if err != nil {
+				return nil, fmt.Errorf("line %v: %v", line+1, err)
+			}
+			ints[i] = n
+		}
+		elapsed, err := time.ParseDuration(row[5])
+		if err != nil {
+			return nil, fmt.Errorf("line %v: %v", line+1, err)
+		}
+		record := experiment.Record{
+			Game:        name,
+			Config1:     ints[0],
+			Config2:     ints[1],
+			P1Wins:      ints[2],
+			P2Wins:      ints[3],
+			Draws:       ints[4],
+			Games:       ints[2] + ints[3] + ints[4],
+			TotalMillis: float64(elapsed) / float64(time.Millisecond),
+		}
+		if len(row) > 6 {
+			if record.Seed, err = strconv.ParseInt(row[6], 10, 64); err != nil {
+				return nil, fmt.Errorf("line %v: %v", line+1, err)
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
@@ -0,0 +1,328 @@
+package report
+
+import (
+	"encoding/base64"
+	"fmt"
+	"github.com/damargulis/game/experiment"
+	"html"
+	"io"
+	"sort"
+	"strings"
+)
+
+type tally struct {
+	wins, draws, losses int
+	millis              float64
+	timedGames          int
+	// selfMillis and selfGames time the engine's games against itself, for
+	// results with no per move timing, such as the old depth sweeps.
+	selfMillis float64
+	selfGames  int
+}
+
+func (t *tally) add(wins, draws, losses int) {
+	t.wins += wins
+	t.draws += draws
+	t.losses += losses
+}
+
+func (t tally) games() int {
+	return t.wins + t.draws + t.losses
+}
+
+func (t tally) score() float64 {
+	return (float64(t.wins) + float64(t.draws)/2) / float64(t.games())
+}
+
+type gameReport struct {
+	name    string
+	engines []experiment.Engine
+	seats   map[[2]experiment.Engine]*tally
+	matches map[[2]experiment.Engine]*tally
+	cross   map[[2]experiment.Engine]*tally
+	totals  map[experiment.Engine]*tally
+}
+
+func label(e experiment.Engine) string {
+	if e.Type == "" {
+		return fmt.Sprintf("depth %v", e.Param)
+	}
+	return e.String()
+}
+
+func get[K comparable](m map[K]*tally, k K) *tally {
+	t, ok := m[k]
+	if !ok {
+		t = new(tally)
+		m[k] = t
+	}
+	return t
+}
+
+func summarize(records []experiment.Record) []*gameReport {
+	var reports []*gameReport
+	byName := map[string]*gameReport{}
+	for _, r := range records {
+		g, ok := byName[r.Game]
+		if !ok {
+			g = &gameReport{
+				name:    r.Game,
+				seats:   map[[2]experiment.Engine]*tally{},
+				matches: map[[2]experiment.Engine]*tally{},
+				cross:   map[[2]experiment.Engine]*tally{},
+				totals:  map[experiment.Engine]*tally{},
+			}
+			byName[r.Game] = g
+			reports = append(reports, g)
+		}
+		a := experiment.Engine{Type: r.Player1, Param: r.Config1}
+		b := experiment.Engine{Type: r.Player2, Param: r.Config2}
+		if r.Decision != "" {
+			// An SPRT match alternates seats, so its P1Wins are a's wins
+			// from either seat.
+			get(g.matches, [2]experiment.Engine{a, b}).add(r.P1Wins, r.Draws, r.P2Wins)
+		} else {
+			get(g.seats, [2]experiment.Engine{a, b}).add(r.P1Wins, r.Draws, r.P2Wins)
+		}
+		get(g.cross, [2]experiment.Engine{a, b}).add(r.P1Wins, r.Draws, r.P2Wins)
+		if a == b {
+			// Mirror matches only show seat advantage, so they stay out
+			// of the engine's score against the field.
+			get(g.totals, a)
+		} else {
+			get(g.cross, [2]experiment.Engine{b, a}).add(r.P2Wins, r.Draws, r.P1Wins)
+			get(g.totals, a).add(r.P1Wins, r.Draws, r.P2Wins)
+			get(g.totals, b).add(r.P2Wins, r.Draws, r.P1Wins)
+		}
+		if r.P1MoveMillis > 0 {
+			t := get(g.totals, a)
+			t.millis += r.P1MoveMillis * float64(r.Games)
+			t.timedGames += r.Games
+		}
+		if r.P2MoveMillis > 0 {
+			t := get(g.totals, b)
+			t.millis += r.P2MoveMillis * float64(r.Games)
+			t.timedGames += r.Games
+		}
+		if a == b && r.P1MoveMillis == 0 && r.P2MoveMillis == 0 && r.TotalMillis > 0 {
+			t := get(g.totals, a)
+			t.selfMillis += r.TotalMillis
+			t.selfGames += r.Games
+		}
+	}
+	for _, g := range reports {
+		for e := range g.totals {
+			g.engines = append(g.engines, e)
+		}
+		sort.Slice(g.engines, func(i, j int) bool {
+			if g.engines[i].Type != g.engines[j].Type {
+				return g.engines[i].Type < g.engines[j].Type
+			}
+			return g.engines[i].Param < g.engines[j].Param
+		})
+	}
+	return reports
+}
+
+func (g *gameReport) labels() []string {
+	labels := make([]string, len(g.engines))
+	for i, e := range g.engines {
+		labels[i] = label(e)
+	}
+	return labels
+}
+
+func (g *gameReport) heatmap(cells map[[2]experiment.Engine]*tally, rowTitle, colTitle string) string {
+	return heatmapSVG(g.labels(), g.labels(), func(i, j int) (cellValue, bool) {
+		t, ok := cells[[2]experiment.Engine{g.engines[i], g.engines[j]}]
+		if !ok || t.games() == 0 {
+			return cellValue{}, false
+		}
+		return cellValue{score: t.score(), games: t.games()}, true
+	}, rowTitle, colTitle)
+}
+
+// heatmaps returns a title and chart for the seated games, scored for
+// Player 1, and for the SPRT matches, scored for the engine they tested.
+func (g *gameReport) heatmaps() [][2]string {
+	var charts [][2]string
+	if len(g.seats) > 0 {
+		charts = append(charts, [2]string{"Player 1 score by pairing", g.heatmap(g.seats, "Player 1", "Player 2")})
+	}
+	if len(g.matches) > 0 {
+		charts = append(charts, [2]string{"Engine A score by SPRT match, over both seats", g.heatmap(g.matches, "Engine A", "Engine B")})
+	}
+	return charts
+}
+
+// strengthCurves plots each engine's performance against the whole field
+// over its mean time per move, one line per engine type. Results without
+// per move timing fall back to the mean length of the engine's games
+// against itself, which the old depth sweeps always played.
+func (g *gameReport) strengthCurves() (string, bool) {
+	perMove := false
+	for _, t := range g.totals {
+		perMove = perMove || t.timedGames > 0
+	}
+	var all []series
+	for _, e := range g.engines {
+		t := g.totals[e]
+		if t.games() == 0 {
+			continue
+		}
+		var x float64
+		switch {
+		case perMove && t.timedGames > 0:
+			x = t.millis / float64(t.timedGames)
+		case !perMove && t.selfGames > 0:
+			x = t.selfMillis / float64(t.selfGames)
+		default:
+			continue
+		}
+		elo, _ := experiment.Elo(t.wins, t.draws, t.losses)
+		p := point{x: x, y: elo, label: label(e)}
+		name := e.Type
+		if name == "" {
+			name = "depth"
+		}
+		if len(all) == 0 || all[len(all)-1].name != name {
+			all = append(all, series{name: name})
+		}
+		all[len(all)-1].points = append(all[len(all)-1].points, p)
+	}
+	if len(all) == 0 {
+		return "", false
+	}
+	for _, s := range all {
+		sort.Slice(s.points, func(i, j int) bool { return s.points[i].x < s.points[j].x })
+	}
+	if !perMove {
+		return curveSVG(all, "mean game time against itself", "Elo against the field"), true
+	}
+	return curveSVG(all, "mean time per move", "Elo against the field"), true
+}
+
+// crosstable returns a header row and one row per engine, each cell the row
+// engine's score against the column engine over both seats.
+func (g *gameReport) crosstable() ([]string, [][]string) {
+	head := append([]string{""}, g.labels()...)
+	head = append(head, "Score", "Games", "Elo")
+	var rows [][]string
+	for _, a := range g.engines {
+		row := []string{label(a)}
+		for _, b := range g.engines {
+			t, ok := g.cross[[2]experiment.Engine{a, b}]
+			if !ok || t.games() == 0 {
+				row = append(row, "")
+				continue
+			}
+			row = append(row, fmt.Sprintf("%g/%v", float64(t.wins)+float64(t.draws)/2, t.games()))
+		}
+		t := g.totals[a]
+		if t.games() == 0 {
+			rows = append(rows, append(row, "", "0", ""))
+			continue
+		}
+		elo, errs := experiment.Elo(t.wins, t.draws, t.losses)
+		row = append(row, fmt.Sprintf("%.1f%%", t.score()*100), fmt.Sprint(t.games()), fmt.Sprintf("%.0f ± %.0f", elo, errs))
+		rows = append(rows, row)
+	}
+	return head, rows
+}
+
+const style = `body{font-family:sans-serif;margin:2em;color:#222}
+table{border-collapse:collapse;font-size:12px;margin-bottom:1em}
+td,th{border:1px solid #ccc;padding:3px 6px;text-align:right}
+th:first-child,td:first-child{text-align:left}
+h2{border-bottom:1px solid #ccc;padding-bottom:4px}`
+
+// Write renders a report for records as a single HTML page, or as Markdown
+// with the charts inlined as data URIs, when format is "md".
+func Write(w io.Writer, records []experiment.Record, format string) error {
+	switch format {
+	case "html":
+		return writeHTML(w, summarize(records))
+	case "md", "markdown":
+		return writeMarkdown(w, summarize(records))
+	}
+	return fmt.Errorf("unknown report format %q", format)
+}
+
+func writeHTML(w io.Writer, reports []*gameReport) error {
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html><head><meta charset=\"utf-8\"><title>Experiment report</title><style>")
+	b.WriteString(style)
+	b.WriteString("</style></head><body>\n<h1>Experiment report</h1>\n")
+	for _, g := range reports {
+		fmt.Fprintf(&b, "<h2>%v</h2>\n", html.EscapeString(g.name))
+		for _, chart := range g.heatmaps() {
+			fmt.Fprintf(&b, "<h3>%v</h3>\n%v\n", chart[0], chart[1])
+		}
+		b.WriteString("<h3>Crosstable</h3>\n<table>\n")
+		head, rows := g.crosstable()
+		b.WriteString("<tr>")
+		for _, h := range head {
+			fmt.Fprintf(&b, "<th>%v</th>", html.EscapeString(h))
+		}
+		b.WriteString("</tr>\n")
+		for _, row := range rows {
+			b.WriteString("<tr>")
+			for i, c := range row {
+				if i == 0 {
+					fmt.Fprintf(&b, "<th>%v</th>", html.EscapeString(c))
+				} else {
+					fmt.Fprintf(&b, "<td>%v</td>", html.EscapeString(c))
+				}
+			}
+			b.WriteString("</tr>\n")
+		}
+		b.WriteString("</table>\n<h3>Time against strength</h3>\n")
+		if svg, ok := g.strengthCurves(); ok {
+			b.WriteString(svg)
+		} else {
+			b.WriteString("<p>No timing in these results.</p>")
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("</body></html>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func dataURI(svg string) string {
+	return "data:image/svg+xml;base64," + base64.StdEncoding.EncodeToString([]byte(svg))
+}
+
+func markdownRow(cells []string) string {
+	escaped := make([]string, len(cells))
+	for i, c := range cells {
+		escaped[i] = strings.ReplaceAll(c, "|", "\\|")
+	}
+	return "| " + strings.Join(escaped, " | ") + " |\n"
+}
+
+func writeMarkdown(w io.Writer, reports []*gameReport) error {
+	var b strings.Builder
+	b.WriteString("# Experiment report\n")
+	for _, g := range reports {
+		fmt.Fprintf(&b, "\n## %v\n\n", g.name)
+		for _, chart := range g.heatmaps() {
+			fmt.Fprintf(&b, "### %v\n\n![%v heatmap](%v)\n\n", chart[0], g.name, dataURI(chart[1]))
+		}
+		b.WriteString("### Crosstable\n\n")
+		head, rows := g.crosstable()
+		b.WriteString(markdownRow(head))
+		b.WriteString("|" + strings.Repeat(" --- |", len(head)) + "\n")
+		for _, row := range rows {
+			b.WriteString(markdownRow(row))
+		}
+		b.WriteString("\n### Time against strength\n\n")
+		if svg, ok := g.strengthCurves(); ok {
+			fmt.Fprintf(&b, "![%v time against strength](%v)\n", g.name, dataURI(svg))
+		} else {
+			b.WriteString("No timing in these results.\n")
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
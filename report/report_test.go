@@ -0,0 +1,131 @@
+package report
+
+import (
+	"bytes"
+	"github.com/damargulis/game/experiment"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// sweep is an old headerless depth sweep: p1depth,p2depth,p1wins,p2wins,
+// ties,time for every pair of depths 0 and 2, mirrors included.
+const sweep = `0,0,40,50,10,1.5s
+2,0,90,5,5,3s
+0,2,10,85,5,3s
+2,2,45,45,10,12s
+`
+
+func TestLegacySweepDrawsTimeAgainstStrength(t *testing.T) {
+	records, err := readCSV(strings.NewReader(sweep), "reversi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	reports := summarize(records)
+	if len(reports) != 1 || reports[0].name != "reversi" {
+		t.Fatalf("summarize() = %v reports, want one for reversi", len(reports))
+	}
+	g := reports[0]
+	deep := g.totals[experiment.Engine{Param: 2}]
+	if deep.selfGames != 100 || deep.selfMillis != 12000 {
+		t.Errorf("depth 2 self play = %v games in %vms, want 100 in 12000ms", deep.selfGames, deep.selfMillis)
+	}
+	svg, ok := g.strengthCurves()
+	if !ok {
+		t.Fatal("strengthCurves() drew nothing for a depth sweep")
+	}
+	for _, want := range []string{"depth 0", "depth 2", "mean game time against itself", "120ms"} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("time against strength chart lacks %q", want)
+		}
+	}
+	var b bytes.Buffer
+	if err := Write(&b, records, "html"); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(b.String(), "No timing") {
+		t.Error("report says a depth sweep has no timing")
+	}
+}
+
+func TestPerMoveTimingWins(t *testing.T) {
+	records := []experiment.Record{
+		{Game: "g", Player1: "A", Config1: 1, Player2: "A", Config2: 1, Games: 2, Draws: 2, TotalMillis: 1000, P1MoveMillis: 5, P2MoveMillis: 5},
+		{Game: "g", Player1: "A", Config1: 1, Player2: "A", Config2: 2, Games: 2, P2Wins: 2, TotalMillis: 1000, P1MoveMillis: 5, P2MoveMillis: 50},
+	}
+	svg, ok := summarize(records)[0].strengthCurves()
+	if !ok {
+		t.Fatal("strengthCurves() drew nothing")
+	}
+	if !strings.Contains(svg, "mean time per move") || !strings.Contains(svg, "50ms") {
+		t.Error("chart does not plot the per move timing")
+	}
+}
+
+func TestSPRTMatchesAreScoredForEngineA(t *testing.T) {
+	a := experiment.Engine{Type: "Alphabeta", Param: 4}
+	b := experiment.Engine{Type: "Alphabeta", Param: 2}
+	records := []experiment.Record{{
+		Game: "connect4", Player1: a.Type, Config1: a.Param, Player2: b.Type, Config2: b.Param,
+		Games: 20, P1Wins: 15, P2Wins: 3, Draws: 2, Decision: experiment.AcceptH1,
+	}}
+	g := summarize(records)[0]
+	if len(g.seats) != 0 {
+		t.Errorf("SPRT match counted as %v seated pairings", len(g.seats))
+	}
+	if got := g.matches[[2]experiment.Engine{a, b}]; got == nil || got.wins != 15 || got.losses != 3 {
+		t.Errorf("match tally = %+v, want a's 15 wins and 3 losses", got)
+	}
+	if got, want := g.cross[[2]experiment.Engine{b, a}], (tally{wins: 3, draws: 2, losses: 15}); got == nil || *got != want {
+		t.Errorf("crosstable b against a = %+v, want %+v", got, want)
+	}
+	var out bytes.Buffer
+	if err := Write(&out, records, "md"); err != nil {
+		t.Fatal(err)
+	}
+	if s := out.String(); strings.Contains(s, "Player 1 score") || !strings.Contains(s, "Engine A score by SPRT match") {
+		t.Errorf("report headings\n%v\nwant the match scored for engine A only", s)
+	}
+}
+
+func TestCrosstableCombinesSeats(t *testing.T) {
+	records := []experiment.Record{
+		{Game: "g", Player1: "A", Config1: 1, Player2: "B", Config2: 1, Games: 4, P1Wins: 3, P2Wins: 1},
+		{Game: "g", Player1: "B", Config1: 1, Player2: "A", Config2: 1, Games: 4, P1Wins: 1, Draws: 2, P2Wins: 1},
+	}
+	head, rows := summarize(records)[0].crosstable()
+	if want := []string{"", "A:1", "B:1", "Score", "Games", "Elo"}; !reflect.DeepEqual(head, want) {
+		t.Fatalf("head = %v, want %v", head, want)
+	}
+	if rows[0][2] != "5/8" || rows[1][1] != "3/8" {
+		t.Errorf("rows = %v, want A 5/8 and B 3/8 against each other", rows)
+	}
+	if rows[0][3] != "62.5%" {
+		t.Errorf("A's score = %v, want 62.5%%", rows[0][3])
+	}
+}
+
+func TestLoadReadsWriterOutput(t *testing.T) {
+	base := filepath.Join(t.TempDir(), "tictactoe")
+	w, err := experiment.NewWriter(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := experiment.Record{Game: "tictactoe", Player1: "Alphabeta", Config1: 3, Player2: "Computer", Games: 2, P1Wins: 1, Draws: 1, Seed: 9, LLR: 1.5, Decision: experiment.Inconclusive}
+	if err := w.Write(want); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for _, ext := range []string{".csv", ".jsonl"} {
+		got, err := Load([]string{base + ext})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 1 || !reflect.DeepEqual(got[0], want) {
+			t.Errorf("Load(%v) = %+v, want %+v", ext, got, want)
+		}
+	}
+}
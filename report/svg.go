@@ -0,0 +1,151 @@
+package report
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"strings"
+)
+
+var palette = []string{
+	"#1f77b4", "#ff7f0e", "#2ca02c", "#d62728", "#9467bd",
+	"#8c564b", "#e377c2", "#7f7f7f", "#bcbd22", "#17becf",
+}
+
+// scoreColor shades a score from red at 0 through light grey at 0.5 to
+// green at 1.
+func scoreColor(score float64) string {
+	lo := [3]float64{215, 48, 39}
+	mid := [3]float64{247, 247, 247}
+	hi := [3]float64{26, 152, 80}
+	from, to, t := lo, mid, score*2
+	if score > 0.5 {
+		from, to, t = mid, hi, score*2-1
+	}
+	var c [3]int
+	for i := range c {
+		c[i] = int(math.Round(from[i] + (to[i]-from[i])*t))
+	}
+	return fmt.Sprintf("#%02x%02x%02x", c[0], c[1], c[2])
+}
+
+type cellValue struct {
+	score float64
+	games int
+}
+
+// heatmapSVG draws rows against columns, filling each cell by score.
+// Missing cells are left blank.
+func heatmapSVG(rows, cols []string, cell func(i, j int) (cellValue, bool), rowTitle, colTitle string) string {
+	const size, left, top = 44, 150, 150
+	width := left + size*len(cols) + 20
+	height := top + size*len(rows) + 20
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%v" height="%v" font-family="sans-serif" font-size="11">`, width, height)
+	fmt.Fprintf(&b, `<text x="%v" y="14" text-anchor="middle" font-weight="bold">%v</text>`, left+size*len(cols)/2, html.EscapeString(colTitle))
+	fmt.Fprintf(&b, `<text x="14" y="%v" text-anchor="middle" font-weight="bold" transform="rotate(-90 14 %v)">%v</text>`, top+size*len(rows)/2, top+size*len(rows)/2, html.EscapeString(rowTitle))
+	for j, c := range cols {
+		x := left + size*j + size/2
+		fmt.Fprintf(&b, `<text x="%v" y="%v" transform="rotate(-60 %v %v)">%v</text>`, x, top-6, x, top-6, html.EscapeString(c))
+	}
+	for i, r := range rows {
+		y := top + size*i
+		fmt.Fprintf(&b, `<text x="%v" y="%v" text-anchor="end">%v</text>`, left-6, y+size/2+4, html.EscapeString(r))
+		for j := range cols {
+			x := left + size*j
+			v, ok := cell(i, j)
+			if !ok {
+				fmt.Fprintf(&b, `<rect x="%v" y="%v" width="%v" height="%v" fill="white" stroke="#ddd"/>`, x, y, size, size)
+				continue
+			}
+			fmt.Fprintf(&b, `<rect x="%v" y="%v" width="%v" height="%v" fill="%v" stroke="white"><title>%v vs %v: %.1f%% of %v games</title></rect>`,
+				x, y, size, size, scoreColor(v.score), html.EscapeString(r), html.EscapeString(cols[j]), v.score*100, v.games)
+			fmt.Fprintf(&b, `<text x="%v" y="%v" text-anchor="middle">%.0f</text>`, x+size/2, y+size/2+4, v.score*100)
+		}
+	}
+	b.WriteString(`</svg>`)
+	return b.String()
+}
+
+type point struct {
+	x, y  float64
+	label string
+}
+
+type series struct {
+	name   string
+	points []point
+}
+
+// curveSVG plots each series as a line over a log scaled x axis.
+func curveSVG(all []series, xTitle, yTitle string) string {
+	const width, height = 680, 400
+	const left, right, top, bottom = 70, 170, 20, 50
+	minX, maxX := math.Inf(1), math.Inf(-1)
+	minY, maxY := math.Inf(1), math.Inf(-1)
+	for _, s := range all {
+		for _, p := range s.points {
+			minX, maxX = math.Min(minX, math.Log10(p.x)), math.Max(maxX, math.Log10(p.x))
+			minY, maxY = math.Min(minY, p.y), math.Max(maxY, p.y)
+		}
+	}
+	minX, maxX = math.Floor(minX), math.Ceil(maxX)
+	if maxX == minX {
+		maxX++
+	}
+	pad := (maxY - minY) * 0.1
+	if pad == 0 {
+		pad = 50
+	}
+	minY, maxY = minY-pad, maxY+pad
+	px := func(x float64) float64 {
+		return left + (math.Log10(x)-minX)/(maxX-minX)*(width-left-right)
+	}
+	py := func(y float64) float64 {
+		return top + (maxY-y)/(maxY-minY)*(height-top-bottom)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%v" height="%v" font-family="sans-serif" font-size="11">`, width, height)
+	fmt.Fprintf(&b, `<rect x="%v" y="%v" width="%v" height="%v" fill="none" stroke="#888"/>`, left, top, width-left-right, height-top-bottom)
+	for e := minX; e <= maxX; e++ {
+		x := left + (e-minX)/(maxX-minX)*(width-left-right)
+		fmt.Fprintf(&b, `<line x1="%.1f" y1="%v" x2="%.1f" y2="%v" stroke="#eee"/>`, x, top, x, height-bottom)
+		fmt.Fprintf(&b, `<text x="%.1f" y="%v" text-anchor="middle">%v</text>`, x, height-bottom+16, formatMillis(math.Pow(10, e)))
+	}
+	for i := 0; i <= 4; i++ {
+		y := minY + (maxY-minY)*float64(i)/4
+		fmt.Fprintf(&b, `<line x1="%v" y1="%.1f" x2="%v" y2="%.1f" stroke="#eee"/>`, left, py(y), width-right, py(y))
+		fmt.Fprintf(&b, `<text x="%v" y="%.1f" text-anchor="end">%.0f</text>`, left-6, py(y)+4, y)
+	}
+	fmt.Fprintf(&b, `<text x="%v" y="%v" text-anchor="middle">%v</text>`, left+(width-left-right)/2, height-10, html.EscapeString(xTitle))
+	fmt.Fprintf(&b, `<text x="16" y="%v" text-anchor="middle" transform="rotate(-90 16 %v)">%v</text>`, top+(height-top-bottom)/2, top+(height-top-bottom)/2, html.EscapeString(yTitle))
+	for i, s := range all {
+		color := palette[i%len(palette)]
+		var coords []string
+		for _, p := range s.points {
+			coords = append(coords, fmt.Sprintf("%.1f,%.1f", px(p.x), py(p.y)))
+		}
+		fmt.Fprintf(&b, `<polyline points="%v" fill="none" stroke="%v" stroke-width="2"/>`, strings.Join(coords, " "), color)
+		for _, p := range s.points {
+			fmt.Fprintf(&b, `<circle cx="%.1f" cy="%.1f" r="3.5" fill="%v"><title>%v: %.0f Elo, %v %v</title></circle>`,
+				px(p.x), py(p.y), color, html.EscapeString(p.label), p.y, html.EscapeString(xTitle), formatMillis(p.x))
+		}
+		ly := top + 16*i + 10
+		fmt.Fprintf(&b, `<rect x="%v" y="%v" width="12" height="12" fill="%v"/>`, width-right+12, ly, color)
+		fmt.Fprintf(&b, `<text x="%v" y="%v">%v</text>`, width-right+30, ly+10, html.EscapeString(s.name))
+	}
+	b.WriteString(`</svg>`)
+	return b.String()
+}
+
+func formatMillis(ms float64) string {
+	switch {
+	case ms >= 1000:
+		return fmt.Sprintf("%.3gs", ms/1000)
+	case ms >= 1:
+		return fmt.Sprintf("%.3gms", ms)
+	default:
+		return fmt.Sprintf("%.3gµs", ms*1000)
+	}
+}